@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Appender is implemented by backends that can add data to the end of an
+// existing location without rewriting it through the caller
+type Appender interface {
+	// Append opens the location for appending, creating it if it does not
+	// exist, and returns a writer positioned at its end
+	Append() (io.WriteCloser, error)
+}
+
+// Append opens the storage location of b for appending.
+// If b does not implement Appender, the existing data is read into memory,
+// the location is re-created and the data written back before the writer
+// is returned. A missing location is simply created.
+func Append(b Backend) (io.WriteCloser, error) {
+	if a, ok := b.(Appender); ok {
+		return a.Append()
+	}
+
+	r, err := b.Open()
+	if errors.Is(err, ErrNotFound) {
+		return b.Create()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: append: %w", err)
+	}
+
+	// Create may truncate the location, so the data must be read first
+	var existing bytes.Buffer
+	_, err = existing.ReadFrom(r)
+	if cerr := r.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: append: read existing data: %w", err)
+	}
+
+	w, err := b.Create()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := existing.WriteTo(w); err != nil {
+		w.Close()
+		return nil, fmt.Errorf("storage: append: rewrite existing data: %w", err)
+	}
+	return w, nil
+}
@@ -0,0 +1,40 @@
+package storage_test
+
+import (
+	"io"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/filesystem"
+	"schneider.vip/hybridbuffer/storage/memory"
+)
+
+func appendString(t *testing.T, b storage.Backend, data string) {
+	t.Helper()
+	w, err := storage.Append(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAppend(t *testing.T) {
+	backends := map[string]storage.Backend{
+		"Fallback": memory.New(),
+		"Appender": filesystem.New(t.TempDir()),
+	}
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			appendString(t, b, "hello")
+			appendString(t, b, " world")
+			if got := read(t, b); got != "hello world" {
+				t.Fatalf("got %q, want %q", got, "hello world")
+			}
+		})
+	}
+}
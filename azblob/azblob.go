@@ -0,0 +1,134 @@
+// Package azblob provides a storage backend that keeps data in an Azure
+// block blob.
+//
+// Backend methods are safe for concurrent use. The blob is only replaced
+// when a writer commits its block list on Close, so Open during a write
+// sees the previous blob, if any. Concurrent writers to the same blob
+// must be avoided as they share block IDs.
+package azblob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+type backend struct {
+	client *blockblob.Client
+	opts   options
+
+	mu       sync.Mutex
+	metadata map[string]*string
+}
+
+// New creates an Azure backend storing its data in blob of container
+func New(client *azblob.Client, container, blob string, opts ...Option) storage.Backend {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	bc := client.ServiceClient().NewContainerClient(container).NewBlockBlobClient(blob)
+	return &backend{client: bc, opts: o}
+}
+
+// SetMetadata sets the metadata stored with blobs written by subsequent
+// Create calls, in addition to the metadata option
+func (b *backend) SetMetadata(md map[string]string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.metadata = make(map[string]*string, len(md))
+	for k, v := range md {
+		b.metadata[k] = &v
+	}
+}
+
+// currentMetadata merges the metadata option with SetMetadata
+func (b *backend) currentMetadata() map[string]*string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.metadata) == 0 {
+		return b.opts.metadata
+	}
+	md := maps.Clone(b.opts.metadata)
+	if md == nil {
+		md = make(map[string]*string, len(b.metadata))
+	}
+	maps.Copy(md, b.metadata)
+	return md
+}
+
+// Create returns a writer that stages blocks and commits them on Close
+func (b *backend) Create() (io.WriteCloser, error) {
+	return b.CreateContext(context.Background())
+}
+
+// CreateContext returns a writer that stages blocks and commits them on
+// Close. Blocks staged before a failure are never committed and are
+// discarded by the service.
+func (b *backend) CreateContext(ctx context.Context) (io.WriteCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return newWriter(ctx, b), nil
+}
+
+// Open returns a stream of the blob content
+func (b *backend) Open() (io.ReadCloser, error) {
+	return b.OpenContext(context.Background())
+}
+
+// OpenContext returns a stream of the blob content bound to ctx
+func (b *backend) OpenContext(ctx context.Context) (io.ReadCloser, error) {
+	resp, err := b.client.DownloadStream(ctx, nil)
+	if err != nil {
+		return nil, mapError("download", err)
+	}
+	return resp.Body, nil
+}
+
+// Remove deletes the blob. Deleting a missing blob is a no-op.
+func (b *backend) Remove() error {
+	return b.RemoveContext(context.Background())
+}
+
+// RemoveContext deletes the blob bound to ctx
+func (b *backend) RemoveContext(ctx context.Context) error {
+	_, err := b.client.Delete(ctx, nil)
+	if err != nil && !bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return mapError("delete", err)
+	}
+	return nil
+}
+
+// Size returns the blob size from its properties
+func (b *backend) Size() (int64, error) {
+	props, err := b.client.GetProperties(context.Background(), nil)
+	if err != nil {
+		return 0, mapError("get properties", err)
+	}
+	if props.ContentLength == nil {
+		return 0, nil
+	}
+	return *props.ContentLength, nil
+}
+
+// Exists reports whether the blob exists
+func (b *backend) Exists() (bool, error) {
+	_, err := b.client.GetProperties(context.Background(), nil)
+	if err != nil {
+		err = mapError("get properties", err)
+		if errors.Is(err, storage.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,31 @@
+package azblob
+
+import (
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+// mapError wraps err and maps Azure failures onto the storage sentinel errors
+func mapError(op string, err error) error {
+	if sentinel := classify(err); sentinel != nil {
+		return fmt.Errorf("azblob: %s: %w: %w", op, sentinel, err)
+	}
+	return fmt.Errorf("azblob: %s: %w", op, err)
+}
+
+func classify(err error) error {
+	switch {
+	case bloberror.HasCode(err, bloberror.BlobNotFound):
+		return storage.ErrNotFound
+	case bloberror.HasCode(err, bloberror.BlobAlreadyExists):
+		return storage.ErrAlreadyExists
+	case bloberror.HasCode(err, bloberror.AuthorizationFailure, bloberror.AuthorizationPermissionMismatch, bloberror.AuthenticationFailed):
+		return storage.ErrPermission
+	case bloberror.HasCode(err, bloberror.ServerBusy, bloberror.InternalError, bloberror.OperationTimedOut):
+		return storage.ErrUnavailable
+	}
+	return nil
+}
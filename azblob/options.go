@@ -0,0 +1,55 @@
+package azblob
+
+import "github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+
+// DefaultBlockSize is the block size used when none is configured
+const DefaultBlockSize = 8 << 20
+
+// Option configures the Azure Blob Storage backend
+type Option func(*options)
+
+type options struct {
+	blockSize   int64
+	tier        *blob.AccessTier
+	metadata    map[string]*string
+	contentType *string
+}
+
+func defaultOptions() options {
+	return options{
+		blockSize: DefaultBlockSize,
+	}
+}
+
+// WithBlockSize sets the size of staged blocks. Each block is buffered
+// in memory before it is uploaded.
+func WithBlockSize(size int64) Option {
+	return func(o *options) {
+		o.blockSize = max(size, 1)
+	}
+}
+
+// WithAccessTier sets the access tier of uploaded blobs
+func WithAccessTier(tier blob.AccessTier) Option {
+	return func(o *options) {
+		o.tier = &tier
+	}
+}
+
+// WithContentType sets the Content-Type of uploaded blobs
+func WithContentType(contentType string) Option {
+	return func(o *options) {
+		o.contentType = &contentType
+	}
+}
+
+// WithMetadata sets metadata stored with uploaded blobs. Metadata set with
+// SetMetadata takes precedence for keys present in both.
+func WithMetadata(md map[string]string) Option {
+	return func(o *options) {
+		o.metadata = make(map[string]*string, len(md))
+		for k, v := range md {
+			o.metadata[k] = &v
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package azblob
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+)
+
+var errWriterClosed = errors.New("azblob: write on closed writer")
+
+// writer buffers one block at a time, stages it and commits the block
+// list on Close
+type writer struct {
+	ctx      context.Context
+	b        *backend
+	metadata map[string]*string
+
+	buf      []byte
+	blockIDs []string
+	err      error
+	closed   bool
+}
+
+func newWriter(ctx context.Context, b *backend) *writer {
+	return &writer{
+		ctx:      ctx,
+		b:        b,
+		metadata: b.currentMetadata(),
+		buf:      make([]byte, 0, b.opts.blockSize),
+	}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errWriterClosed
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), cap(w.buf)-len(w.buf))
+		w.buf = append(w.buf, p[:n]...)
+		p = p[n:]
+		written += n
+
+		if len(w.buf) == cap(w.buf) {
+			if err := w.stage(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (w *writer) Close() error {
+	if w.closed {
+		return w.err
+	}
+	w.closed = true
+	if w.err != nil {
+		return w.err
+	}
+
+	if len(w.buf) > 0 {
+		if err := w.stage(); err != nil {
+			return err
+		}
+	}
+	_, err := w.b.client.CommitBlockList(w.ctx, w.blockIDs, &blockblob.CommitBlockListOptions{
+		Metadata: w.metadata,
+		Tier:     w.b.opts.tier,
+		HTTPHeaders: &blob.HTTPHeaders{
+			BlobContentType: w.b.opts.contentType,
+		},
+	})
+	if err != nil {
+		w.err = mapError("commit block list", err)
+	}
+	return w.err
+}
+
+func (w *writer) stage() error {
+	var id [4]byte
+	binary.BigEndian.PutUint32(id[:], uint32(len(w.blockIDs)))
+	blockID := base64.StdEncoding.EncodeToString(id[:])
+
+	body := streaming.NopCloser(bytes.NewReader(w.buf))
+	if _, err := w.b.client.StageBlock(w.ctx, blockID, body, nil); err != nil {
+		w.err = mapError("stage block", err)
+		return w.err
+	}
+	w.blockIDs = append(w.blockIDs, blockID)
+	w.buf = w.buf[:0]
+	return nil
+}
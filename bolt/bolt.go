@@ -0,0 +1,183 @@
+// Package bolt provides a storage backend that keeps data as a single
+// key in a bbolt database, so all spills of a process can live in one
+// self-contained file.
+//
+// Values are buffered in memory by the writer and held in memory again
+// during the write transaction, so the backend is meant for small and
+// medium spills; see DefaultMaxSize.
+//
+// Backend methods are safe for concurrent use. The value is only stored
+// when a writer is closed, so Open during a write sees the previous value,
+// if any.
+package bolt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.etcd.io/bbolt"
+	berrors "go.etcd.io/bbolt/errors"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+// ErrValueTooLarge is returned by the writer's Close when more data was
+// written than the configured maximum size
+var ErrValueTooLarge = errors.New("bolt: value exceeds maximum size")
+
+type backend struct {
+	db     *bbolt.DB
+	bucket []byte
+	key    []byte
+	opts   options
+}
+
+// New creates a bbolt backend storing its data under key in bucket.
+// The bucket is created on the first Create.
+func New(db *bbolt.DB, bucket, key string, opts ...Option) storage.Backend {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &backend{db: db, bucket: []byte(bucket), key: []byte(key), opts: o}
+}
+
+// Create returns a writer that buffers the value and stores it on Close
+func (b *backend) Create() (io.WriteCloser, error) {
+	return &writer{b: b}, nil
+}
+
+// Open reads the value and returns a reader over a copy of it
+func (b *backend) Open() (io.ReadCloser, error) {
+	var data []byte
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		v := b.get(tx)
+		if v == nil {
+			return storage.ErrNotFound
+		}
+		// Values are only valid during the transaction
+		data = bytes.Clone(v)
+		return nil
+	})
+	if err != nil {
+		return nil, mapError("get", err)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Remove deletes the key. Removing a missing key is a no-op.
+func (b *backend) Remove() error {
+	err := b.db.Update(func(tx *bbolt.Tx) error {
+		bkt := tx.Bucket(b.bucket)
+		if bkt == nil {
+			return nil
+		}
+		return bkt.Delete(b.key)
+	})
+	if err != nil {
+		return mapError("delete", err)
+	}
+	return nil
+}
+
+// Size returns the length of the stored value
+func (b *backend) Size() (int64, error) {
+	var n int64
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		v := b.get(tx)
+		if v == nil {
+			return storage.ErrNotFound
+		}
+		n = int64(len(v))
+		return nil
+	})
+	if err != nil {
+		return 0, mapError("size", err)
+	}
+	return n, nil
+}
+
+// Exists reports whether the key exists
+func (b *backend) Exists() (bool, error) {
+	var ok bool
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		ok = b.get(tx) != nil
+		return nil
+	})
+	if err != nil {
+		return false, mapError("exists", err)
+	}
+	return ok, nil
+}
+
+// get returns the stored value or nil if the bucket or key is missing
+func (b *backend) get(tx *bbolt.Tx) []byte {
+	bkt := tx.Bucket(b.bucket)
+	if bkt == nil {
+		return nil
+	}
+	return bkt.Get(b.key)
+}
+
+type writer struct {
+	b        *backend
+	buf      bytes.Buffer
+	n        int64
+	tooLarge bool
+	closed   bool
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("bolt: write on closed writer")
+	}
+	w.n += int64(len(p))
+	if w.n > w.b.opts.maxSize {
+		// Keep accepting writes but stop buffering; Close reports the error
+		w.tooLarge = true
+		w.buf = bytes.Buffer{}
+		return len(p), nil
+	}
+	return w.buf.Write(p)
+}
+
+// Close stores the buffered value in a single write transaction
+func (w *writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.tooLarge {
+		return fmt.Errorf("bolt: put %s: %d bytes: %w", w.b.key, w.n, ErrValueTooLarge)
+	}
+
+	err := w.b.db.Update(func(tx *bbolt.Tx) error {
+		bkt, err := tx.CreateBucketIfNotExists(w.b.bucket)
+		if err != nil {
+			return err
+		}
+		// Put keeps a reference to the value until the transaction commits
+		return bkt.Put(w.b.key, w.buf.Bytes())
+	})
+	if err != nil {
+		return mapError("put", err)
+	}
+	return nil
+}
+
+// mapError wraps err and maps bbolt failures onto the storage sentinel errors
+func mapError(op string, err error) error {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return fmt.Errorf("bolt: %s: %w", op, err)
+	case errors.Is(err, berrors.ErrDatabaseReadOnly), errors.Is(err, berrors.ErrTxNotWritable):
+		return fmt.Errorf("bolt: %s: %w: %w", op, storage.ErrPermission, err)
+	case errors.Is(err, berrors.ErrDatabaseNotOpen), errors.Is(err, berrors.ErrTimeout):
+		return fmt.Errorf("bolt: %s: %w: %w", op, storage.ErrUnavailable, err)
+	case errors.Is(err, berrors.ErrValueTooLarge):
+		return fmt.Errorf("bolt: %s: %w: %w", op, ErrValueTooLarge, err)
+	}
+	return fmt.Errorf("bolt: %s: %w", op, err)
+}
@@ -0,0 +1,107 @@
+package bolt_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"go.etcd.io/bbolt"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/bolt"
+	"schneider.vip/hybridbuffer/storage/storagetest"
+)
+
+func openDB(t *testing.T) *bbolt.DB {
+	t.Helper()
+	db, err := bbolt.Open(filepath.Join(t.TempDir(), "spill.db"), 0o600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSuite(t *testing.T) {
+	db := openDB(t)
+	var n int
+	storagetest.RunSuite(t, func() storage.Backend {
+		n++
+		return bolt.New(db, "spills", fmt.Sprintf("key-%d", n))
+	})
+}
+
+func TestKeysAreIndependent(t *testing.T) {
+	db := openDB(t)
+	a := bolt.New(db, "spills", "a")
+	b := bolt.New(db, "spills", "b")
+
+	for _, kv := range []struct {
+		b    storage.Backend
+		data string
+	}{{a, "first"}, {b, "second"}} {
+		w, err := kv.b.Create()
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, kv.data)
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.Remove(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := b.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if data, _ := io.ReadAll(r); string(data) != "second" {
+		t.Fatalf("got %q, want %q", data, "second")
+	}
+}
+
+func TestValueTooLarge(t *testing.T) {
+	b := bolt.New(openDB(t), "spills", "key", bolt.WithMaxSize(10))
+
+	w, err := b.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, "more than ten bytes"); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); !errors.Is(err, bolt.ErrValueTooLarge) {
+		t.Fatalf("Close: got %v, want ErrValueTooLarge", err)
+	}
+	if ok, err := storage.Exists(b); err != nil || ok {
+		t.Fatalf("Exists = %v, %v after a failed write", ok, err)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spill.db")
+	db, err := bbolt.Open(path, 0o600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	db, err = bbolt.Open(path, 0o600, &bbolt.Options{ReadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	w, err := bolt.New(db, "spills", "key").Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(w, "data")
+	if err := w.Close(); !errors.Is(err, storage.ErrPermission) {
+		t.Fatalf("Close: got %v, want ErrPermission", err)
+	}
+}
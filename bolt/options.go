@@ -0,0 +1,28 @@
+package bolt
+
+// DefaultMaxSize is the largest value the writer accepts by default.
+// bbolt itself allows values up to bbolt.MaxValueSize (about 2 GiB), but
+// every value is held in memory during its write transaction and grows
+// the database file, so large values are better kept elsewhere.
+const DefaultMaxSize = 64 << 20
+
+// Option configures the bbolt backend
+type Option func(*options)
+
+type options struct {
+	maxSize int64
+}
+
+func defaultOptions() options {
+	return options{
+		maxSize: DefaultMaxSize,
+	}
+}
+
+// WithMaxSize sets the largest value the writer accepts (default
+// DefaultMaxSize). Larger values fail on Close with ErrValueTooLarge.
+func WithMaxSize(n int64) Option {
+	return func(o *options) {
+		o.maxSize = n
+	}
+}
@@ -0,0 +1,80 @@
+// Package buffered provides a storage backend wrapper that buffers the
+// returned streams to reduce the number of calls into the backend
+package buffered
+
+import (
+	"bufio"
+	"errors"
+	"io"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+// DefaultSize is the buffer size used when Wrap is called with size <= 0
+const DefaultSize = 64 << 10
+
+type backend struct {
+	inner storage.Backend
+	size  int
+}
+
+// Wrap returns a backend whose readers and writers are buffered with
+// size bytes. A size <= 0 selects DefaultSize.
+func Wrap(b storage.Backend, size int) storage.Backend {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	return &backend{inner: b, size: size}
+}
+
+// Create returns a buffered writer that is flushed on Close
+func (b *backend) Create() (io.WriteCloser, error) {
+	w, err := b.inner.Create()
+	if err != nil {
+		return nil, err
+	}
+	return &writer{Writer: bufio.NewWriterSize(w, b.size), inner: w}, nil
+}
+
+// Open returns a buffered reader
+func (b *backend) Open() (io.ReadCloser, error) {
+	r, err := b.inner.Open()
+	if err != nil {
+		return nil, err
+	}
+	return &reader{Reader: bufio.NewReaderSize(r, b.size), inner: r}, nil
+}
+
+// Remove removes the wrapped location
+func (b *backend) Remove() error {
+	return b.inner.Remove()
+}
+
+type writer struct {
+	*bufio.Writer
+	inner io.WriteCloser
+}
+
+// Sync flushes buffered data and syncs the underlying writer
+func (w *writer) Sync() error {
+	if err := w.Writer.Flush(); err != nil {
+		return err
+	}
+	return storage.Sync(w.inner)
+}
+
+// Close flushes buffered data and closes the underlying writer. Errors
+// of the flush and the close are both reported.
+func (w *writer) Close() error {
+	err := w.Writer.Flush()
+	return errors.Join(err, storage.CloseAll(w.inner))
+}
+
+type reader struct {
+	*bufio.Reader
+	inner io.ReadCloser
+}
+
+func (r *reader) Close() error {
+	return r.inner.Close()
+}
@@ -0,0 +1,16 @@
+package buffered_test
+
+import (
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/buffered"
+	"schneider.vip/hybridbuffer/storage/memory"
+	"schneider.vip/hybridbuffer/storage/storagetest"
+)
+
+func TestSuite(t *testing.T) {
+	storagetest.RunSuite(t, func() storage.Backend {
+		return buffered.Wrap(memory.New(), 4<<10)
+	})
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+var errEmptyChain = errors.New("storage: chain: no backends")
+
+type chainBackend struct {
+	backends []Backend
+
+	// active is the backend that holds the data, or nil if unknown
+	active Backend
+}
+
+// Chain returns a backend that uses the first of backends that works.
+// Create tries each backend in order until one succeeds and remembers it,
+// so that subsequent Open and Remove calls target the same backend.
+// Only a failing Create triggers a fallback; errors reported later by the
+// returned writer are passed through unchanged.
+//
+// Without a prior Create, Open probes the backends in order and moves on
+// to the next one only if a backend reports ErrNotFound, and Remove
+// removes the location from every backend.
+//
+// If all backends fail, the errors of every backend are joined.
+func Chain(backends ...Backend) Backend {
+	return &chainBackend{backends: backends}
+}
+
+// Create creates the location on the first backend that accepts it
+func (c *chainBackend) Create() (io.WriteCloser, error) {
+	if len(c.backends) == 0 {
+		return nil, errEmptyChain
+	}
+
+	var errs []error
+	for i, b := range c.backends {
+		w, err := b.Create()
+		if err == nil {
+			c.active = b
+			return w, nil
+		}
+		errs = append(errs, fmt.Errorf("backend %d: %w", i, err))
+	}
+	c.active = nil
+	return nil, chainError(errs)
+}
+
+// Open opens the location on the backend that won Create, or on the first
+// backend that has it
+func (c *chainBackend) Open() (io.ReadCloser, error) {
+	if c.active != nil {
+		return c.active.Open()
+	}
+	if len(c.backends) == 0 {
+		return nil, errEmptyChain
+	}
+
+	var errs []error
+	for i, b := range c.backends {
+		r, err := b.Open()
+		if err == nil {
+			c.active = b
+			return r, nil
+		}
+		errs = append(errs, fmt.Errorf("backend %d: %w", i, err))
+		if !errors.Is(err, ErrNotFound) {
+			break
+		}
+	}
+	return nil, chainError(errs)
+}
+
+// Remove removes the location from the backend that won Create, or from
+// every backend if none is known
+func (c *chainBackend) Remove() error {
+	if c.active != nil {
+		if err := c.active.Remove(); err != nil {
+			return err
+		}
+		c.active = nil
+		return nil
+	}
+
+	var errs []error
+	for i, b := range c.backends {
+		if err := b.Remove(); err != nil {
+			errs = append(errs, fmt.Errorf("backend %d: %w", i, err))
+		}
+	}
+	return chainError(errs)
+}
+
+// chainError joins the errors of all backends, or returns nil if there are none
+func chainError(errs []error) error {
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("storage: chain: %w", err)
+	}
+	return nil
+}
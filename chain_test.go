@@ -0,0 +1,64 @@
+package storage_test
+
+import (
+	"errors"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/memory"
+)
+
+func TestChainFallsBackOnCreate(t *testing.T) {
+	second := memory.New()
+	c := storage.Chain(&broken{Backend: memory.New(), createErr: errBroken}, second)
+	write(t, c, "data")
+
+	if got := read(t, second); got != "data" {
+		t.Fatalf("second backend holds %q", got)
+	}
+	if got := read(t, c); got != "data" {
+		t.Fatalf("chain reads %q", got)
+	}
+	if err := c.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := storage.Exists(second); ok {
+		t.Fatal("Remove did not reach the backend that won Create")
+	}
+}
+
+func TestChainAllFail(t *testing.T) {
+	errOther := errors.New("other")
+	c := storage.Chain(
+		&broken{Backend: memory.New(), createErr: errBroken},
+		&broken{Backend: memory.New(), createErr: errOther},
+	)
+	_, err := c.Create()
+	if !errors.Is(err, errBroken) || !errors.Is(err, errOther) {
+		t.Fatalf("got %v, want the errors of both backends", err)
+	}
+}
+
+func TestChainOpenProbes(t *testing.T) {
+	second := memory.New()
+	write(t, second, "found")
+	if got := read(t, storage.Chain(memory.New(), second)); got != "found" {
+		t.Fatalf("got %q, want the data of the second backend", got)
+	}
+
+	// Errors other than ErrNotFound stop the probing
+	c := storage.Chain(&broken{Backend: memory.New(), openErr: errBroken}, second)
+	if _, err := c.Open(); !errors.Is(err, errBroken) {
+		t.Fatalf("got %v, want the first backend's error", err)
+	}
+}
+
+func TestChainEmpty(t *testing.T) {
+	c := storage.Chain()
+	if _, err := c.Create(); err == nil {
+		t.Fatal("Create on an empty chain succeeded")
+	}
+	if _, err := c.Open(); err == nil {
+		t.Fatal("Open on an empty chain succeeded")
+	}
+}
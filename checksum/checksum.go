@@ -0,0 +1,233 @@
+// Package checksum provides a storage backend wrapper that verifies the
+// integrity of stored data.
+//
+// The checksum is computed while writing and either appended to the data
+// as a fixed-size trailer, which is stripped transparently on Open, or
+// stored in a sidecar location. Readers report ErrChecksumMismatch from
+// the final Read and from Close if the data does not match.
+package checksum
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+// ErrChecksumMismatch is returned when stored data does not match its checksum
+var ErrChecksumMismatch = errors.New("checksum: checksum mismatch")
+
+type backend struct {
+	inner storage.Backend
+	opts  options
+}
+
+// Wrap returns a backend that checksums data written to b and verifies
+// it when read back
+func Wrap(b storage.Backend, opts ...Option) storage.Backend {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &backend{inner: b, opts: o}
+}
+
+// Create returns a writer that checksums the data written through it
+func (b *backend) Create() (io.WriteCloser, error) {
+	w, err := b.inner.Create()
+	if err != nil {
+		return nil, err
+	}
+	return &writer{w: w, h: b.opts.algorithm.new(), sidecar: b.opts.sidecar}, nil
+}
+
+// Open returns a reader that verifies the checksum at the end of the data
+func (b *backend) Open() (io.ReadCloser, error) {
+	h := b.opts.algorithm.new()
+
+	var want []byte
+	if b.opts.sidecar != nil {
+		sum, err := readSidecar(b.opts.sidecar, h.Size())
+		if err != nil {
+			return nil, err
+		}
+		want = sum
+	}
+
+	r, err := b.inner.Open()
+	if err != nil {
+		return nil, err
+	}
+	return &reader{r: r, h: h, want: want}, nil
+}
+
+// Remove removes the data and the sidecar location, if any
+func (b *backend) Remove() error {
+	err := b.inner.Remove()
+	if b.opts.sidecar != nil {
+		if serr := b.opts.sidecar.Remove(); serr != nil {
+			err = errors.Join(err, fmt.Errorf("checksum: remove sidecar: %w", serr))
+		}
+	}
+	return err
+}
+
+func readSidecar(b storage.Backend, size int) ([]byte, error) {
+	r, err := b.Open()
+	if err != nil {
+		return nil, fmt.Errorf("checksum: open sidecar: %w", err)
+	}
+	defer r.Close()
+
+	sum := make([]byte, size+1)
+	n, err := io.ReadFull(r, sum)
+	switch {
+	case err == io.ErrUnexpectedEOF && n == size:
+		return sum[:size], nil
+	case err == nil, err == io.EOF, err == io.ErrUnexpectedEOF:
+		// The sidecar is longer or shorter than a checksum
+		return nil, ErrChecksumMismatch
+	}
+	return nil, fmt.Errorf("checksum: read sidecar: %w", err)
+}
+
+type writer struct {
+	w       io.WriteCloser
+	h       hash.Hash
+	sidecar storage.Backend
+	err     error
+	closed  bool
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.w.Write(p)
+	w.h.Write(p[:n])
+	if err != nil {
+		w.err = err
+	}
+	return n, err
+}
+
+// Close writes the checksum as trailer or sidecar and closes the writer.
+// After a failed Write no checksum is written, so the truncated data does
+// not verify, and Close returns the Write error. The error of the first
+// Close is returned by every later call.
+func (w *writer) Close() error {
+	if w.closed {
+		return w.err
+	}
+	w.closed = true
+	w.err = w.close()
+	return w.err
+}
+
+func (w *writer) close() error {
+	if w.sidecar != nil {
+		// Drop the old checksum before the new data is committed, so that
+		// a failure below cannot leave it next to data it does not describe
+		if err := w.sidecar.Remove(); err != nil {
+			err = fmt.Errorf("checksum: remove sidecar: %w", err)
+			return errors.Join(w.err, err, storage.CloseAll(w.w))
+		}
+	}
+	if w.err != nil {
+		return errors.Join(w.err, storage.CloseAll(w.w))
+	}
+
+	sum := w.h.Sum(nil)
+	if w.sidecar == nil {
+		_, err := w.w.Write(sum)
+		return errors.Join(err, storage.CloseAll(w.w))
+	}
+
+	if err := w.w.Close(); err != nil {
+		return err
+	}
+	sw, err := w.sidecar.Create()
+	if err != nil {
+		return fmt.Errorf("checksum: create sidecar: %w", err)
+	}
+	_, err = sw.Write(sum)
+	if err := errors.Join(err, storage.CloseAll(sw)); err != nil {
+		return fmt.Errorf("checksum: write sidecar: %w", err)
+	}
+	return nil
+}
+
+// reader hashes the data and verifies it at EOF. Without a sidecar
+// checksum (want == nil) the last h.Size() bytes are held back as trailer.
+type reader struct {
+	r       io.ReadCloser
+	h       hash.Hash
+	want    []byte
+	buf     []byte
+	pending []byte
+	eof     bool
+	err     error
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	hold := 0
+	if r.want == nil {
+		hold = r.h.Size()
+	}
+
+	for {
+		if avail := len(r.pending) - hold; avail > 0 {
+			n := copy(p, r.pending[:avail])
+			r.h.Write(p[:n])
+			r.pending = r.pending[n:]
+			return n, nil
+		}
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.eof {
+			r.err = r.verify(hold)
+			continue
+		}
+
+		if r.buf == nil {
+			r.buf = make([]byte, 32<<10)
+		}
+		n, err := r.r.Read(r.buf)
+		r.pending = append(r.pending, r.buf[:n]...)
+		switch {
+		case err == io.EOF:
+			r.eof = true
+		case err != nil:
+			r.err = err
+		}
+	}
+}
+
+// Close closes the underlying reader. It reports ErrChecksumMismatch if
+// the data was read to the end and did not match.
+func (r *reader) Close() error {
+	err := r.r.Close()
+	if errors.Is(r.err, ErrChecksumMismatch) {
+		return r.err
+	}
+	return err
+}
+
+func (r *reader) verify(hold int) error {
+	want := r.want
+	if want == nil {
+		if len(r.pending) != hold {
+			return ErrChecksumMismatch
+		}
+		want = r.pending
+	}
+	if !bytes.Equal(r.h.Sum(nil), want) {
+		return ErrChecksumMismatch
+	}
+	r.pending = nil
+	return io.EOF
+}
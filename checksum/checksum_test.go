@@ -0,0 +1,191 @@
+package checksum_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/checksum"
+	"schneider.vip/hybridbuffer/storage/memory"
+	"schneider.vip/hybridbuffer/storage/storagetest"
+)
+
+func TestSuite(t *testing.T) {
+	storagetest.RunSuite(t, func() storage.Backend {
+		return checksum.Wrap(memory.New())
+	})
+}
+
+func TestSuiteSidecar(t *testing.T) {
+	storagetest.RunSuite(t, func() storage.Backend {
+		return checksum.Wrap(memory.New(), checksum.WithSidecar(memory.New()))
+	})
+}
+
+func TestCorruption(t *testing.T) {
+	tests := []struct {
+		name    string
+		sidecar bool
+		alg     checksum.Algorithm
+		tamper  func([]byte) []byte
+	}{
+		{"FlippedBit", false, checksum.CRC32C, flip},
+		{"FlippedBitSHA256", false, checksum.SHA256, flip},
+		{"Truncated", false, checksum.CRC32C, func(p []byte) []byte { return p[:len(p)-1] }},
+		{"TooShortForTrailer", false, checksum.SHA256, func(p []byte) []byte { return p[:4] }},
+		{"SidecarFlippedBit", true, checksum.CRC32C, flip},
+		{"SidecarAppended", true, checksum.SHA256, func(p []byte) []byte { return append(p, 'x') }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := memory.New()
+			opts := []checksum.Option{checksum.WithAlgorithm(tt.alg)}
+			if tt.sidecar {
+				opts = append(opts, checksum.WithSidecar(memory.New()))
+			}
+			b := checksum.Wrap(inner, opts...)
+
+			write(t, b, "some payload that will be tampered with")
+			write(t, inner, string(tt.tamper([]byte(read(t, inner)))))
+
+			r, err := b.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := io.ReadAll(r); !errors.Is(err, checksum.ErrChecksumMismatch) {
+				t.Errorf("Read: got %v, want ErrChecksumMismatch", err)
+			}
+			if err := r.Close(); !errors.Is(err, checksum.ErrChecksumMismatch) {
+				t.Errorf("Close: got %v, want ErrChecksumMismatch", err)
+			}
+		})
+	}
+}
+
+func TestIntactDataVerifies(t *testing.T) {
+	b := checksum.Wrap(memory.New(), checksum.WithAlgorithm(checksum.SHA256))
+	write(t, b, "payload")
+	if got := read(t, b); got != "payload" {
+		t.Fatalf("got %q, want %q", got, "payload")
+	}
+}
+
+var errInner = errors.New("inner failure")
+
+// failing wraps a backend whose writers fail on Write or Close once the
+// corresponding flag is set
+type failing struct {
+	storage.Backend
+	failWrite bool
+	failClose bool
+}
+
+func (f *failing) Create() (io.WriteCloser, error) {
+	w, err := f.Backend.Create()
+	if err != nil {
+		return nil, err
+	}
+	return &failingWriter{w: w, f: f}, nil
+}
+
+type failingWriter struct {
+	w io.WriteCloser
+	f *failing
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.f.failWrite {
+		return 0, errInner
+	}
+	return w.w.Write(p)
+}
+
+func (w *failingWriter) Close() error {
+	if w.f.failClose {
+		return errInner
+	}
+	return w.w.Close()
+}
+
+func TestFailedWriteRemovesSidecar(t *testing.T) {
+	for _, mode := range []string{"Write", "Close"} {
+		t.Run(mode, func(t *testing.T) {
+			inner := &failing{Backend: memory.New()}
+			sidecar := memory.New()
+			b := checksum.Wrap(inner, checksum.WithSidecar(sidecar))
+			write(t, b, "old data")
+
+			inner.failWrite = mode == "Write"
+			inner.failClose = mode == "Close"
+			w, err := b.Create()
+			if err != nil {
+				t.Fatal(err)
+			}
+			io.WriteString(w, "new data")
+			if err := w.Close(); !errors.Is(err, errInner) {
+				t.Fatalf("Close: got %v, want the inner error", err)
+			}
+
+			if ok, _ := storage.Exists(sidecar); ok {
+				t.Fatal("stale sidecar was left behind")
+			}
+			if _, err := b.Open(); !errors.Is(err, storage.ErrNotFound) {
+				t.Fatalf("Open: got %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestCloseErrorIsSticky(t *testing.T) {
+	for _, sidecar := range []bool{false, true} {
+		inner := &failing{Backend: memory.New(), failClose: true}
+		var opts []checksum.Option
+		if sidecar {
+			opts = append(opts, checksum.WithSidecar(memory.New()))
+		}
+		w, err := checksum.Wrap(inner, opts...).Create()
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, "data")
+		for i := range 2 {
+			if err := w.Close(); !errors.Is(err, errInner) {
+				t.Errorf("sidecar %v: Close #%d: got %v, want the inner error", sidecar, i+1, err)
+			}
+		}
+	}
+}
+
+func flip(p []byte) []byte {
+	p[0] ^= 1
+	return p
+}
+
+func write(t *testing.T, b storage.Backend, data string) {
+	t.Helper()
+	w, err := b.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func read(t *testing.T, b storage.Backend) string {
+	t.Helper()
+	r, err := b.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,54 @@
+package checksum
+
+import (
+	"crypto/sha256"
+	"hash"
+	"hash/crc32"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+// Algorithm selects the checksum function
+type Algorithm int
+
+const (
+	// CRC32C is the Castagnoli CRC-32 (4 byte checksum)
+	CRC32C Algorithm = iota
+
+	// SHA256 is SHA-256 (32 byte checksum)
+	SHA256
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+func (a Algorithm) new() hash.Hash {
+	if a == SHA256 {
+		return sha256.New()
+	}
+	return crc32.New(castagnoli)
+}
+
+// Option configures the checksumming wrapper
+type Option func(*options)
+
+type options struct {
+	algorithm Algorithm
+	sidecar   storage.Backend
+}
+
+// WithAlgorithm selects the checksum function (default CRC32C)
+func WithAlgorithm(a Algorithm) Option {
+	return func(o *options) {
+		o.algorithm = a
+	}
+}
+
+// WithSidecar stores the checksum in a separate location instead of
+// appending it as a trailer to the data. The old sidecar is removed before
+// new data is committed, so after a failed write Open reports an error
+// wrapping ErrNotFound instead of checking the data against a stale sum.
+func WithSidecar(b storage.Backend) Option {
+	return func(o *options) {
+		o.sidecar = b
+	}
+}
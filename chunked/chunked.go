@@ -0,0 +1,295 @@
+// Package chunked provides a storage backend that splits data across
+// several locations of a bounded size.
+//
+// Chunks are stored in locations named "chunk-0", "chunk-1", ... of the
+// Locator, and a "manifest" location holds a manifest.Manifest describing
+// them. The manifest is written last, so Open reports storage.ErrNotFound
+// until a write has completed. Readers verify the size and checksum
+// recorded in the manifest once all chunks have been read.
+package chunked
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/manifest"
+)
+
+const manifestName = "manifest"
+
+// maxManifestSize bounds how much is read from the manifest location
+const maxManifestSize = 4 << 10
+
+// ErrCorrupt is returned by Read if the chunks do not match the size or
+// checksum recorded in the manifest
+var ErrCorrupt = errors.New("chunked: data does not match manifest")
+
+var errClosed = errors.New("chunked: use of closed stream")
+
+type backend struct {
+	locator   storage.Locator
+	chunkSize int64
+}
+
+// Wrap returns a backend that stores data in chunks of at most chunkSize
+// bytes in locations minted by locator
+func Wrap(locator storage.Locator, chunkSize int64) storage.Backend {
+	return &backend{locator: locator, chunkSize: max(chunkSize, 1)}
+}
+
+func chunkName(i int) string {
+	return fmt.Sprintf("chunk-%d", i)
+}
+
+func (b *backend) chunk(i int) storage.Backend {
+	return b.locator.Location(chunkName(i))
+}
+
+// Create removes any previously stored chunks and returns a writer that
+// starts a new chunk every chunkSize bytes
+func (b *backend) Create() (io.WriteCloser, error) {
+	if err := b.Remove(); err != nil {
+		return nil, err
+	}
+	return &writer{b: b}, nil
+}
+
+// Open returns a reader that concatenates all chunks in order
+func (b *backend) Open() (io.ReadCloser, error) {
+	m, err := b.readManifest()
+	if err != nil {
+		return nil, err
+	}
+	return &reader{b: b, m: m}, nil
+}
+
+// Remove deletes the manifest and every chunk, including chunks left
+// behind by an incomplete write. A corrupt or unsupported manifest is
+// removed as well, and the chunks are then probed one by one.
+func (b *backend) Remove() error {
+	m, err := b.readManifest()
+	if err != nil && !errors.Is(err, storage.ErrNotFound) &&
+		!errors.Is(err, manifest.ErrInvalid) && !errors.Is(err, manifest.ErrUnsupportedVersion) {
+		return err
+	}
+	if err != nil {
+		m = manifest.Manifest{}
+	}
+	count := int(m.ChunkCount)
+	if err := b.locator.Location(manifestName).Remove(); err != nil {
+		return fmt.Errorf("chunked: remove manifest: %w", err)
+	}
+
+	for i := 0; ; i++ {
+		c := b.chunk(i)
+		if i >= count {
+			ok, err := storage.Exists(c)
+			if err != nil {
+				return fmt.Errorf("chunked: probe %s: %w", chunkName(i), err)
+			}
+			if !ok {
+				return nil
+			}
+		}
+		if err := c.Remove(); err != nil {
+			return fmt.Errorf("chunked: remove %s: %w", chunkName(i), err)
+		}
+	}
+}
+
+func (b *backend) readManifest() (manifest.Manifest, error) {
+	var m manifest.Manifest
+	r, err := b.locator.Location(manifestName).Open()
+	if err != nil {
+		return m, fmt.Errorf("chunked: open manifest: %w", err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(io.LimitReader(r, maxManifestSize))
+	if err != nil {
+		return m, fmt.Errorf("chunked: read manifest: %w", err)
+	}
+	if err := m.UnmarshalBinary(data); err != nil {
+		return m, fmt.Errorf("chunked: read manifest: %w", err)
+	}
+	return m, nil
+}
+
+func (b *backend) writeManifest(m manifest.Manifest) error {
+	data, err := m.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("chunked: write manifest: %w", err)
+	}
+	w, err := b.locator.Location(manifestName).Create()
+	if err != nil {
+		return fmt.Errorf("chunked: create manifest: %w", err)
+	}
+	_, err = w.Write(data)
+	if err := errors.Join(err, w.Close()); err != nil {
+		return fmt.Errorf("chunked: write manifest: %w", err)
+	}
+	return nil
+}
+
+type writer struct {
+	b      *backend
+	cur    io.WriteCloser
+	n      int64
+	count  int
+	total  int64
+	crc    uint32
+	err    error
+	closed bool
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errClosed
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	written := 0
+	for len(p) > 0 {
+		if w.cur == nil {
+			cw, err := w.b.chunk(w.count).Create()
+			if err != nil {
+				w.err = fmt.Errorf("chunked: create %s: %w", chunkName(w.count), err)
+				return written, w.err
+			}
+			w.cur, w.n = cw, 0
+			w.count++
+		}
+
+		n := int(min(int64(len(p)), w.b.chunkSize-w.n))
+		n, err := w.cur.Write(p[:n])
+		w.crc = manifest.Checksum(w.crc, p[:n])
+		written += n
+		w.n += int64(n)
+		w.total += int64(n)
+		p = p[n:]
+		if err != nil {
+			w.err = err
+			return written, err
+		}
+
+		if w.n == w.b.chunkSize {
+			if err := w.closeChunk(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Close closes the last chunk and commits the manifest. After a failed
+// write the chunks written so far are removed instead.
+func (w *writer) Close() error {
+	if w.closed {
+		return w.err
+	}
+	w.closed = true
+
+	if w.err == nil {
+		w.closeChunk()
+	}
+	if w.err == nil {
+		w.err = w.b.writeManifest(manifest.Manifest{
+			ChunkCount: int64(w.count),
+			ChunkSize:  w.b.chunkSize,
+			TotalBytes: w.total,
+			Checksum:   w.crc,
+		})
+	}
+	if w.err != nil {
+		if w.cur != nil {
+			w.cur.Close()
+		}
+		if rerr := w.b.Remove(); rerr != nil {
+			w.err = errors.Join(w.err, rerr)
+		}
+	}
+	return w.err
+}
+
+func (w *writer) closeChunk() error {
+	if w.cur == nil {
+		return nil
+	}
+	err := w.cur.Close()
+	w.cur = nil
+	if err != nil {
+		w.err = fmt.Errorf("chunked: close %s: %w", chunkName(w.count-1), err)
+	}
+	return w.err
+}
+
+// reader opens one chunk at a time and reads them back to back
+type reader struct {
+	b      *backend
+	m      manifest.Manifest
+	next   int
+	cur    io.ReadCloser
+	total  int64
+	crc    uint32
+	closed bool
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if r.closed {
+		return 0, errClosed
+	}
+	for {
+		if r.cur == nil {
+			if r.next >= int(r.m.ChunkCount) {
+				return 0, r.verify()
+			}
+			cr, err := r.b.chunk(r.next).Open()
+			if err != nil {
+				return 0, fmt.Errorf("chunked: open %s: %w", chunkName(r.next), err)
+			}
+			r.cur = cr
+			r.next++
+		}
+
+		n, err := r.cur.Read(p)
+		r.crc = manifest.Checksum(r.crc, p[:n])
+		r.total += int64(n)
+		if r.total > r.m.TotalBytes {
+			return n, ErrCorrupt
+		}
+		if err == io.EOF {
+			err = r.cur.Close()
+			r.cur = nil
+			if err != nil {
+				return n, err
+			}
+			if n == 0 {
+				continue
+			}
+		}
+		return n, err
+	}
+}
+
+// verify checks the data read against the manifest at the end of the data
+func (r *reader) verify() error {
+	if r.total != r.m.TotalBytes || r.crc != r.m.Checksum {
+		return ErrCorrupt
+	}
+	return io.EOF
+}
+
+func (r *reader) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if r.cur == nil {
+		return nil
+	}
+	return r.cur.Close()
+}
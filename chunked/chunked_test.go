@@ -0,0 +1,145 @@
+package chunked_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/chunked"
+	"schneider.vip/hybridbuffer/storage/manifest"
+	"schneider.vip/hybridbuffer/storage/memory"
+	"schneider.vip/hybridbuffer/storage/storagetest"
+)
+
+func TestSuite(t *testing.T) {
+	storagetest.RunSuite(t, func() storage.Backend {
+		return chunked.Wrap(memory.NewLocator(), 1<<20)
+	})
+}
+
+func TestChunkLayout(t *testing.T) {
+	l := memory.NewLocator()
+	b := chunked.Wrap(l, 4)
+	write(t, b, "0123456789")
+
+	for i, want := range []string{"0123", "4567", "89"} {
+		if got := read(t, l.Location(fmt.Sprintf("chunk-%d", i))); got != want {
+			t.Errorf("chunk-%d holds %q, want %q", i, got, want)
+		}
+	}
+	mustExist(t, l.Location("chunk-3"), false)
+	if got := read(t, b); got != "0123456789" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestShrinkingRemovesStaleChunks(t *testing.T) {
+	l := memory.NewLocator()
+	b := chunked.Wrap(l, 4)
+	write(t, b, "0123456789")
+	write(t, b, "abc")
+
+	mustExist(t, l.Location("chunk-0"), true)
+	mustExist(t, l.Location("chunk-1"), false)
+	mustExist(t, l.Location("chunk-2"), false)
+	if got := read(t, b); got != "abc" {
+		t.Fatalf("got %q, want %q", got, "abc")
+	}
+}
+
+func TestManifestMismatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		chunk string
+		data  string
+	}{
+		{"ChangedContent", "chunk-1", "4X67"},
+		{"ShorterChunk", "chunk-1", "45"},
+		{"LongerChunk", "chunk-2", "89abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := memory.NewLocator()
+			b := chunked.Wrap(l, 4)
+			write(t, b, "0123456789")
+			write(t, l.Location(tt.chunk), tt.data)
+
+			if _, err := readAll(b); !errors.Is(err, chunked.ErrCorrupt) {
+				t.Fatalf("got %v, want ErrCorrupt", err)
+			}
+		})
+	}
+}
+
+func TestMissingChunk(t *testing.T) {
+	l := memory.NewLocator()
+	b := chunked.Wrap(l, 4)
+	write(t, b, "0123456789")
+	l.Location("chunk-1").Remove()
+
+	if _, err := readAll(b); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("got %v, want ErrNotFound", err)
+	}
+}
+
+func TestRemoveWithCorruptManifest(t *testing.T) {
+	l := memory.NewLocator()
+	b := chunked.Wrap(l, 4)
+	write(t, b, "0123456789")
+	write(t, l.Location("manifest"), "garbage")
+
+	if _, err := readAll(b); !errors.Is(err, manifest.ErrInvalid) {
+		t.Fatalf("Open: got %v, want manifest.ErrInvalid", err)
+	}
+	if err := b.Remove(); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	for _, name := range []string{"manifest", "chunk-0", "chunk-1", "chunk-2"} {
+		mustExist(t, l.Location(name), false)
+	}
+}
+
+func mustExist(t *testing.T, b storage.Backend, want bool) {
+	t.Helper()
+	ok, err := storage.Exists(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok != want {
+		t.Fatalf("Exists: got %v, want %v", ok, want)
+	}
+}
+
+func write(t *testing.T, b storage.Backend, data string) {
+	t.Helper()
+	w, err := b.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readAll(b storage.Backend) ([]byte, error) {
+	r, err := b.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func read(t *testing.T, b storage.Backend) string {
+	t.Helper()
+	data, err := readAll(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
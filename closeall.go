@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"reflect"
+)
+
+// CloseAll closes every closer in order, even if earlier ones fail, and
+// returns the errors joined with errors.Join. Nil closers are skipped,
+// including typed nil pointers such as a (*os.File)(nil).
+//
+// Wrappers that own several closers, such as a compressor and the
+// underlying writer, use it so that no Close error of an inner layer is
+// masked by an outer one.
+func CloseAll(closers ...io.Closer) error {
+	var errs []error
+	for _, c := range closers {
+		if isNil(c) {
+			continue
+		}
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isNil reports whether c is nil or an interface holding a nil value
+func isNil(c io.Closer) bool {
+	if c == nil {
+		return true
+	}
+	v := reflect.ValueOf(c)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
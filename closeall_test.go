@@ -0,0 +1,112 @@
+package storage_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/buffered"
+	"schneider.vip/hybridbuffer/storage/compress"
+	"schneider.vip/hybridbuffer/storage/crypto"
+	"schneider.vip/hybridbuffer/storage/memory"
+)
+
+type closer struct {
+	err    error
+	closed bool
+}
+
+func (c *closer) Close() error {
+	c.closed = true
+	return c.err
+}
+
+func TestCloseAll(t *testing.T) {
+	errOuter := errors.New("outer")
+	errInner := errors.New("inner")
+	outer := &closer{err: errOuter}
+	inner := &closer{err: errInner}
+	ok := &closer{}
+
+	err := storage.CloseAll(outer, nil, ok, inner)
+	if !errors.Is(err, errOuter) || !errors.Is(err, errInner) {
+		t.Fatalf("got %v, want both close errors", err)
+	}
+	for i, c := range []*closer{outer, ok, inner} {
+		if !c.closed {
+			t.Errorf("closer %d was not closed", i)
+		}
+	}
+}
+
+func TestCloseAllSkipsTypedNil(t *testing.T) {
+	var f *os.File
+	var c *closer
+	if err := storage.CloseAll(f, c, io.Closer(nil)); err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+}
+
+var (
+	errWrite = errors.New("write failed")
+	errClose = errors.New("close failed")
+)
+
+// faultyBackend hands out writers that fail on Close, and on Write once
+// failWrites is set
+type faultyBackend struct {
+	storage.Backend
+	w *faultyWriter
+}
+
+func (b *faultyBackend) Create() (io.WriteCloser, error) {
+	b.w = &faultyWriter{}
+	return b.w, nil
+}
+
+type faultyWriter struct {
+	failWrites bool
+}
+
+func (w *faultyWriter) Write(p []byte) (int, error) {
+	if w.failWrites {
+		return 0, errWrite
+	}
+	return len(p), nil
+}
+
+func (w *faultyWriter) Close() error {
+	return errClose
+}
+
+// TestCloseAllStacked closes a stack of wrappers whose innermost writer
+// fails both the final flush and its Close. Every layer flushes on Close,
+// so both errors have to travel up through all of them.
+func TestCloseAllStacked(t *testing.T) {
+	faulty := &faultyBackend{Backend: memory.New()}
+	key := bytes.Repeat([]byte{0x42}, 32)
+	eb, err := crypto.New(faulty, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := buffered.Wrap(compress.Wrap(eb), 4<<10)
+
+	w, err := b.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, "still in a buffer"); err != nil {
+		t.Fatal(err)
+	}
+
+	faulty.w.failWrites = true
+	err = w.Close()
+	for _, want := range []error{errWrite, errClose} {
+		if !errors.Is(err, want) {
+			t.Errorf("Close: got %v, want it to wrap %v", err, want)
+		}
+	}
+}
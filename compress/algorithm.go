@@ -0,0 +1,89 @@
+package compress
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Names of the built-in algorithms
+const (
+	Gzip = "gzip"
+	Zstd = "zstd"
+)
+
+// DefaultLevel selects the algorithm's default compression level
+const DefaultLevel = -1
+
+// Algorithm creates compressing writers and decompressing readers
+type Algorithm interface {
+	// NewWriter returns a writer compressing into w at the given level
+	NewWriter(w io.Writer, level int) (io.WriteCloser, error)
+
+	// NewReader returns a reader decompressing r
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Algorithm{
+		Gzip: gzipAlgorithm{},
+		Zstd: zstdAlgorithm{},
+	}
+)
+
+// Register makes an algorithm available under name, replacing any
+// algorithm previously registered under the same name
+func Register(name string, a Algorithm) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = a
+}
+
+func lookup(name string) (Algorithm, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	if a, ok := registry[name]; ok {
+		return a, nil
+	}
+	names := make([]string, 0, len(registry))
+	for n := range registry {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("compress: unknown algorithm %q (registered: %s)", name, strings.Join(names, ", "))
+}
+
+type gzipAlgorithm struct{}
+
+func (gzipAlgorithm) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	return gzip.NewWriterLevel(w, level)
+}
+
+func (gzipAlgorithm) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+type zstdAlgorithm struct{}
+
+func (zstdAlgorithm) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	var opts []zstd.EOption
+	if level != DefaultLevel {
+		opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	}
+	return zstd.NewWriter(w, opts...)
+}
+
+func (zstdAlgorithm) NewReader(r io.Reader) (io.ReadCloser, error) {
+	d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return d.IOReadCloser(), nil
+}
@@ -0,0 +1,94 @@
+// Package compress provides a storage backend wrapper that transparently
+// compresses stored data
+package compress
+
+import (
+	"fmt"
+	"io"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+type backend struct {
+	inner storage.Backend
+	opts  options
+}
+
+// Wrap returns a backend that compresses data written to b and
+// decompresses it when read back
+func Wrap(b storage.Backend, opts ...Option) storage.Backend {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &backend{inner: b, opts: o}
+}
+
+// Create returns a writer that compresses into the wrapped backend
+func (b *backend) Create() (io.WriteCloser, error) {
+	alg, err := lookup(b.opts.algorithm)
+	if err != nil {
+		return nil, err
+	}
+	w, err := b.inner.Create()
+	if err != nil {
+		return nil, err
+	}
+	zw, err := alg.NewWriter(w, b.opts.level)
+	if err != nil {
+		w.Close()
+		return nil, fmt.Errorf("compress: %w", err)
+	}
+	return &writer{zw: zw, inner: w}, nil
+}
+
+// Open returns a reader that decompresses data from the wrapped backend
+func (b *backend) Open() (io.ReadCloser, error) {
+	alg, err := lookup(b.opts.algorithm)
+	if err != nil {
+		return nil, err
+	}
+	r, err := b.inner.Open()
+	if err != nil {
+		return nil, err
+	}
+	zr, err := alg.NewReader(r)
+	if err != nil {
+		r.Close()
+		return nil, fmt.Errorf("compress: %w", err)
+	}
+	return &reader{zr: zr, inner: r}, nil
+}
+
+// Remove removes the wrapped location
+func (b *backend) Remove() error {
+	return b.inner.Remove()
+}
+
+type writer struct {
+	zw    io.WriteCloser
+	inner io.WriteCloser
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	return w.zw.Write(p)
+}
+
+// Close flushes the compressor and closes the underlying writer,
+// reporting the errors of both
+func (w *writer) Close() error {
+	return storage.CloseAll(w.zw, w.inner)
+}
+
+type reader struct {
+	zr    io.ReadCloser
+	inner io.ReadCloser
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	return r.zr.Read(p)
+}
+
+func (r *reader) Close() error {
+	return storage.CloseAll(r.zr, r.inner)
+}
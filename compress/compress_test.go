@@ -0,0 +1,16 @@
+package compress_test
+
+import (
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/compress"
+	"schneider.vip/hybridbuffer/storage/memory"
+	"schneider.vip/hybridbuffer/storage/storagetest"
+)
+
+func TestSuite(t *testing.T) {
+	storagetest.RunSuite(t, func() storage.Backend {
+		return compress.Wrap(memory.New())
+	})
+}
@@ -0,0 +1,31 @@
+package compress
+
+// Option configures the compressing wrapper
+type Option func(*options)
+
+type options struct {
+	algorithm string
+	level     int
+}
+
+func defaultOptions() options {
+	return options{
+		algorithm: Gzip,
+		level:     DefaultLevel,
+	}
+}
+
+// WithAlgorithm selects a registered compression algorithm (default gzip)
+func WithAlgorithm(name string) Option {
+	return func(o *options) {
+		o.algorithm = name
+	}
+}
+
+// WithLevel sets the compression level. Its meaning depends on the
+// algorithm; DefaultLevel selects the algorithm's default.
+func WithLevel(level int) Option {
+	return func(o *options) {
+		o.level = level
+	}
+}
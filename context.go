@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// BackendContext extends Backend with context-aware operations.
+// Implementations should abort in-flight I/O on the returned streams
+// once the context is done and report ctx.Err()
+type BackendContext interface {
+	Backend
+
+	// CreateContext creates a new storage location bound to ctx
+	CreateContext(ctx context.Context) (io.WriteCloser, error)
+
+	// OpenContext opens an existing storage location bound to ctx
+	OpenContext(ctx context.Context) (io.ReadCloser, error)
+
+	// RemoveContext removes the storage location bound to ctx
+	RemoveContext(ctx context.Context) error
+}
+
+// CreateContext creates a storage location on b bound to ctx.
+// If b does not implement BackendContext, the plain Create is used and
+// the returned writer is closed and the location removed when ctx is done.
+// The writer implements Syncer and passes Sync on to the plain writer.
+func CreateContext(ctx context.Context, b Backend) (io.WriteCloser, error) {
+	if bc, ok := b.(BackendContext); ok {
+		return bc.CreateContext(ctx)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	w, err := b.Create()
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		w.Close()
+		b.Remove()
+		return nil, err
+	}
+
+	cw := &ctxWriter{ctx: ctx, w: w, done: make(chan struct{})}
+	cw.stop = context.AfterFunc(ctx, func() {
+		defer close(cw.done)
+		cw.closeInner()
+		b.Remove()
+	})
+	return cw, nil
+}
+
+// OpenContext opens the storage location on b bound to ctx.
+// If b does not implement BackendContext, the plain Open is used and
+// the returned reader is closed when ctx is done. It implements io.Seeker
+// if the plain reader does.
+func OpenContext(ctx context.Context, b Backend) (io.ReadCloser, error) {
+	if bc, ok := b.(BackendContext); ok {
+		return bc.OpenContext(ctx)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r, err := b.Open()
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		r.Close()
+		return nil, err
+	}
+
+	cr := &ctxReader{ctx: ctx, r: r, done: make(chan struct{})}
+	cr.stop = context.AfterFunc(ctx, func() {
+		defer close(cr.done)
+		cr.closeInner()
+	})
+	if s, ok := r.(io.Seeker); ok {
+		return &ctxReadSeeker{ctxReader: cr, s: s}, nil
+	}
+	return cr, nil
+}
+
+// RemoveContext removes the storage location on b bound to ctx.
+// If b does not implement BackendContext, ctx is only checked before
+// the plain Remove is called.
+func RemoveContext(ctx context.Context, b Backend) error {
+	if bc, ok := b.(BackendContext); ok {
+		return bc.RemoveContext(ctx)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return b.Remove()
+}
+
+// ctxWriter aborts the wrapped writer once its context is done
+type ctxWriter struct {
+	ctx  context.Context
+	w    io.WriteCloser
+	stop func() bool
+	once sync.Once
+	err  error
+
+	// done is closed once the cleanup after cancellation has finished
+	done chan struct{}
+}
+
+func (w *ctxWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := w.w.Write(p)
+	if err != nil {
+		if cerr := w.ctx.Err(); cerr != nil {
+			return n, cerr
+		}
+	}
+	return n, err
+}
+
+// Sync syncs the wrapped writer if it supports it
+func (w *ctxWriter) Sync() error {
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	return Sync(w.w)
+}
+
+func (w *ctxWriter) Close() error {
+	if !w.stop() {
+		// The context fired first; wait until the location has been
+		// cleaned up so a following Create is not removed as well
+		<-w.done
+		return w.ctx.Err()
+	}
+	w.closeInner()
+	return w.err
+}
+
+func (w *ctxWriter) closeInner() {
+	w.once.Do(func() { w.err = w.w.Close() })
+}
+
+// ctxReader aborts the wrapped reader once its context is done
+type ctxReader struct {
+	ctx  context.Context
+	r    io.ReadCloser
+	stop func() bool
+	once sync.Once
+	err  error
+	done chan struct{}
+}
+
+func (r *ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF {
+		if cerr := r.ctx.Err(); cerr != nil {
+			return n, cerr
+		}
+	}
+	return n, err
+}
+
+func (r *ctxReader) Close() error {
+	if !r.stop() {
+		<-r.done
+		return r.ctx.Err()
+	}
+	r.closeInner()
+	return r.err
+}
+
+func (r *ctxReader) closeInner() {
+	r.once.Do(func() { r.err = r.r.Close() })
+}
+
+// ctxReadSeeker is a ctxReader over a seekable stream
+type ctxReadSeeker struct {
+	*ctxReader
+	s io.Seeker
+}
+
+func (r *ctxReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.s.Seek(offset, whence)
+}
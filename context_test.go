@@ -0,0 +1,118 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/memory"
+)
+
+func TestCreateContextCancelledBefore(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	b := memory.New()
+	if _, err := storage.CreateContext(ctx, b); !errors.Is(err, context.Canceled) {
+		t.Fatalf("CreateContext: got %v, want context.Canceled", err)
+	}
+	write(t, b, "data")
+	if _, err := storage.OpenContext(ctx, b); !errors.Is(err, context.Canceled) {
+		t.Fatalf("OpenContext: got %v, want context.Canceled", err)
+	}
+}
+
+func TestCreateContextCancelledMidWrite(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := memory.New()
+	w, err := storage.CreateContext(ctx, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, "partial"); err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	if _, err := io.WriteString(w, "more"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Write: got %v, want context.Canceled", err)
+	}
+	if err := w.Close(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Close: got %v, want context.Canceled", err)
+	}
+	if ok, _ := storage.Exists(b); ok {
+		t.Fatal("partial data was committed")
+	}
+
+	// Close waited for the cleanup, so it cannot remove this write
+	write(t, b, "next")
+	if got := read(t, b); got != "next" {
+		t.Fatalf("got %q, want %q", got, "next")
+	}
+}
+
+func TestCreateContextCancelledAfterClose(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := memory.New()
+	w, err := storage.CreateContext(ctx, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(w, "data")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	if got := read(t, b); got != "data" {
+		t.Fatalf("got %q, want %q", got, "data")
+	}
+}
+
+func TestOpenContextCancelledMidRead(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := memory.New()
+	write(t, b, "data")
+
+	r, err := storage.OpenContext(ctx, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := r.Read(make([]byte, 4)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Read: got %v, want context.Canceled", err)
+	}
+	if err := r.Close(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Close: got %v, want context.Canceled", err)
+	}
+}
+
+func write(t *testing.T, b storage.Backend, data string) {
+	t.Helper()
+	w, err := b.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func read(t *testing.T, b storage.Backend) string {
+	t.Helper()
+	r, err := b.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Copy streams the data stored in src into a newly created location on dst
+// and returns the number of bytes copied. If copying fails after dst was
+// created, dst is removed so no partial data is left behind.
+//
+// The data is copied with io.Copy, which uses the io.WriterTo of the
+// source reader or the io.ReaderFrom of the destination writer if present.
+// The filesystem and s3 backends implement them to avoid an intermediate
+// buffer.
+func Copy(dst, src Backend) (int64, error) {
+	r, err := src.Open()
+	if err != nil {
+		return 0, fmt.Errorf("storage: copy: open source: %w", err)
+	}
+
+	w, err := dst.Create()
+	if err != nil {
+		r.Close()
+		return 0, fmt.Errorf("storage: copy: create destination: %w", err)
+	}
+
+	n, err := io.Copy(w, r)
+	if err != nil {
+		err = fmt.Errorf("storage: copy: %w", err)
+	}
+	if cerr := w.Close(); cerr != nil && err == nil {
+		err = fmt.Errorf("storage: copy: close destination: %w", cerr)
+	}
+	if cerr := r.Close(); cerr != nil && err == nil {
+		err = fmt.Errorf("storage: copy: close source: %w", cerr)
+	}
+
+	if err != nil {
+		if rerr := dst.Remove(); rerr != nil {
+			err = errors.Join(err, fmt.Errorf("storage: copy: remove destination: %w", rerr))
+		}
+		return n, err
+	}
+	return n, nil
+}
+
+// Move copies src to dst and removes src once the copy has succeeded
+func Move(dst, src Backend) (int64, error) {
+	n, err := Copy(dst, src)
+	if err != nil {
+		return n, err
+	}
+	if err := src.Remove(); err != nil {
+		return n, fmt.Errorf("storage: move: remove source: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,84 @@
+package storage_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/memory"
+)
+
+// failingSource yields some data and then fails
+type failingSource struct {
+	storage.Backend
+}
+
+func (s failingSource) Open() (io.ReadCloser, error) {
+	return io.NopCloser(io.MultiReader(
+		io.LimitReader(zeros{}, 100),
+		errReader{errBroken},
+	)), nil
+}
+
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+func TestCopy(t *testing.T) {
+	src, dst := memory.New(), memory.New()
+	write(t, src, "data")
+
+	n, err := storage.Copy(dst, src)
+	if err != nil || n != 4 {
+		t.Fatalf("Copy = %d, %v", n, err)
+	}
+	if got := read(t, dst); got != "data" {
+		t.Fatalf("destination holds %q", got)
+	}
+	if got := read(t, src); got != "data" {
+		t.Fatalf("source holds %q", got)
+	}
+}
+
+func TestCopyRemovesPartialDestination(t *testing.T) {
+	dst := memory.New()
+	if _, err := storage.Copy(dst, failingSource{memory.New()}); !errors.Is(err, errBroken) {
+		t.Fatalf("got %v, want the source's error", err)
+	}
+	if ok, _ := storage.Exists(dst); ok {
+		t.Fatal("partial destination was left behind")
+	}
+}
+
+func TestCopyMissingSource(t *testing.T) {
+	dst := memory.New()
+	if _, err := storage.Copy(dst, memory.New()); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("got %v, want ErrNotFound", err)
+	}
+	if ok, _ := storage.Exists(dst); ok {
+		t.Fatal("destination was created")
+	}
+}
+
+func TestMove(t *testing.T) {
+	src, dst := memory.New(), memory.New()
+	write(t, src, "data")
+
+	if _, err := storage.Move(dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(t, dst); got != "data" {
+		t.Fatalf("destination holds %q", got)
+	}
+	if ok, _ := storage.Exists(src); ok {
+		t.Fatal("source was not removed")
+	}
+}
@@ -0,0 +1,138 @@
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/crypto"
+	"schneider.vip/hybridbuffer/storage/memory"
+	"schneider.vip/hybridbuffer/storage/storagetest"
+)
+
+var key = bytes.Repeat([]byte{0x42}, crypto.KeySize)
+
+func TestSuite(t *testing.T) {
+	storagetest.RunSuite(t, func() storage.Backend {
+		return newBackend(t, memory.New(), key)
+	})
+}
+
+func TestInvalidKey(t *testing.T) {
+	if _, err := crypto.New(memory.New(), key[:16]); !errors.Is(err, crypto.ErrInvalidKey) {
+		t.Fatalf("got %v, want ErrInvalidKey", err)
+	}
+}
+
+// With a frame size of 4, the 12 byte payload is stored as an 8 byte
+// header and three frames of 37 bytes: flags, length, nonce, 4 bytes of
+// ciphertext and the tag. The last frame is marked final.
+const (
+	payload    = "0123456789ab"
+	headerSize = 8
+	frameSize  = 1 + 4 + 12 + 4 + 16
+)
+
+func TestTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func([]byte) []byte
+	}{
+		{"FlippedBit", func(p []byte) []byte {
+			p[len(p)-1] ^= 1
+			return p
+		}},
+		{"FlippedFlags", func(p []byte) []byte {
+			p[headerSize] ^= 1
+			return p
+		}},
+		{"TruncatedMidFrame", func(p []byte) []byte { return p[:len(p)-1] }},
+		{"MissingFinalFrame", func(p []byte) []byte { return p[:headerSize+2*frameSize] }},
+		{"HeaderOnly", func(p []byte) []byte { return p[:headerSize] }},
+		{"ReorderedFrames", func(p []byte) []byte {
+			first := bytes.Clone(p[headerSize : headerSize+frameSize])
+			copy(p[headerSize:], p[headerSize+frameSize:headerSize+2*frameSize])
+			copy(p[headerSize+frameSize:], first)
+			return p
+		}},
+		{"TrailingData", func(p []byte) []byte { return append(p, 0) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := memory.New()
+			b := newBackend(t, inner, key, crypto.WithFrameSize(4))
+			write(t, b, payload)
+
+			stored := raw(t, inner)
+			if len(stored) != headerSize+3*frameSize {
+				t.Fatalf("stored %d bytes, want %d", len(stored), headerSize+3*frameSize)
+			}
+			write(t, inner, string(tt.tamper(stored)))
+
+			if _, err := readAll(b); !errors.Is(err, crypto.ErrAuthFailed) {
+				t.Fatalf("got %v, want ErrAuthFailed", err)
+			}
+		})
+	}
+}
+
+func TestWrongKey(t *testing.T) {
+	inner := memory.New()
+	write(t, newBackend(t, inner, key), payload)
+
+	other := bytes.Repeat([]byte{0x24}, crypto.KeySize)
+	if _, err := readAll(newBackend(t, inner, other)); !errors.Is(err, crypto.ErrAuthFailed) {
+		t.Fatalf("got %v, want ErrAuthFailed", err)
+	}
+}
+
+func TestCiphertextHidesPlaintext(t *testing.T) {
+	inner := memory.New()
+	write(t, newBackend(t, inner, key), payload)
+	if bytes.Contains(raw(t, inner), []byte(payload)) {
+		t.Fatal("stored data contains the plaintext")
+	}
+}
+
+func newBackend(t *testing.T, b storage.Backend, key []byte, opts ...crypto.Option) storage.Backend {
+	t.Helper()
+	eb, err := crypto.New(b, key, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return eb
+}
+
+func write(t *testing.T, b storage.Backend, data string) {
+	t.Helper()
+	w, err := b.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readAll(b storage.Backend) ([]byte, error) {
+	r, err := b.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func raw(t *testing.T, b storage.Backend) []byte {
+	t.Helper()
+	data, err := readAll(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -0,0 +1,32 @@
+package crypto
+
+const (
+	// DefaultFrameSize is the plaintext size of a single encrypted frame
+	DefaultFrameSize = 64 << 10
+
+	// MaxFrameSize is the largest accepted frame size
+	MaxFrameSize = 16 << 20
+)
+
+// Option configures the encrypting wrapper
+type Option func(*options)
+
+type options struct {
+	frameSize int
+}
+
+func defaultOptions() options {
+	return options{
+		frameSize: DefaultFrameSize,
+	}
+}
+
+// WithFrameSize sets the plaintext size of each encrypted frame. Larger
+// frames reduce overhead but are buffered in memory while streaming.
+// The value is clamped to [1, MaxFrameSize]. Readers take the frame size
+// from the stream header, so it only affects writing.
+func WithFrameSize(size int) Option {
+	return func(o *options) {
+		o.frameSize = min(max(size, 1), MaxFrameSize)
+	}
+}
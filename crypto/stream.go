@@ -0,0 +1,232 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+// Stream layout:
+//
+//	header: magic (4) | frame size (4)
+//	frame:  flags (1) | ciphertext length (4) | nonce (12) | ciphertext
+//
+// The additional data of every frame is header | frame index (8) | flags.
+const (
+	magic          = "HBC1"
+	headerSize     = 8
+	frameHeadSize  = 5
+	flagFinal      = 1
+	additionalSize = headerSize + 8 + 1
+)
+
+var errClosed = errors.New("crypto: use of closed stream")
+
+type writer struct {
+	w      io.WriteCloser
+	aead   cipher.AEAD
+	header [headerSize]byte
+	buf    []byte
+	out    []byte
+	index  uint64
+	err    error
+	closed bool
+}
+
+func newWriter(w io.WriteCloser, aead cipher.AEAD, frameSize int) (*writer, error) {
+	ew := &writer{
+		w:    w,
+		aead: aead,
+		buf:  make([]byte, 0, frameSize),
+	}
+	copy(ew.header[:], magic)
+	binary.BigEndian.PutUint32(ew.header[4:], uint32(frameSize))
+	if _, err := w.Write(ew.header[:]); err != nil {
+		return nil, err
+	}
+	return ew, nil
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errClosed
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	written := 0
+	for len(p) > 0 {
+		if len(w.buf) == cap(w.buf) {
+			if err := w.flush(0); err != nil {
+				return written, err
+			}
+		}
+		n := min(len(p), cap(w.buf)-len(w.buf))
+		w.buf = append(w.buf, p[:n]...)
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close writes the final frame and closes the underlying writer
+func (w *writer) Close() error {
+	if w.closed {
+		return w.err
+	}
+	w.closed = true
+
+	err := w.err
+	if err == nil {
+		err = w.flush(flagFinal)
+	}
+	w.err = errors.Join(err, storage.CloseAll(w.w))
+	return w.err
+}
+
+func (w *writer) flush(flags byte) error {
+	nonceSize := w.aead.NonceSize()
+	size := frameHeadSize + nonceSize + len(w.buf) + w.aead.Overhead()
+	if cap(w.out) < size {
+		w.out = make([]byte, 0, size)
+	}
+	out := w.out[:frameHeadSize+nonceSize]
+
+	out[0] = flags
+	binary.BigEndian.PutUint32(out[1:], uint32(len(w.buf)+w.aead.Overhead()))
+	nonce := out[frameHeadSize:]
+	if _, err := rand.Read(nonce); err != nil {
+		w.err = fmt.Errorf("crypto: %w", err)
+		return w.err
+	}
+
+	out = w.aead.Seal(out, nonce, w.buf, additionalData(w.header[:], w.index, flags))
+	if _, err := w.w.Write(out); err != nil {
+		w.err = err
+		return err
+	}
+	w.index++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+type reader struct {
+	r      io.ReadCloser
+	aead   cipher.AEAD
+	header [headerSize]byte
+	maxLen int
+	frame  []byte
+	plain  []byte
+	index  uint64
+	final  bool
+	err    error
+	closed bool
+}
+
+func newReader(r io.ReadCloser, aead cipher.AEAD) *reader {
+	return &reader{r: r, aead: aead, maxLen: -1}
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if r.closed {
+		return 0, errClosed
+	}
+	for len(r.plain) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.err = r.next()
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+func (r *reader) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	return r.r.Close()
+}
+
+// next decrypts the following frame into r.plain
+func (r *reader) next() error {
+	if r.maxLen < 0 {
+		if err := r.readHeader(); err != nil {
+			return err
+		}
+	}
+	if r.final {
+		// Nothing may follow the final frame
+		var b [1]byte
+		if n, _ := io.ReadFull(r.r, b[:]); n != 0 {
+			return ErrAuthFailed
+		}
+		return io.EOF
+	}
+
+	nonceSize := r.aead.NonceSize()
+	var head [frameHeadSize]byte
+	if _, err := io.ReadFull(r.r, head[:]); err != nil {
+		return truncated(err)
+	}
+	flags := head[0]
+	n := int(binary.BigEndian.Uint32(head[1:]))
+	if flags&^flagFinal != 0 || n < r.aead.Overhead() || n > r.maxLen {
+		return ErrAuthFailed
+	}
+
+	if cap(r.frame) < nonceSize+n {
+		r.frame = make([]byte, nonceSize+n)
+	}
+	frame := r.frame[:nonceSize+n]
+	if _, err := io.ReadFull(r.r, frame); err != nil {
+		return truncated(err)
+	}
+
+	plain, err := r.aead.Open(frame[nonceSize:nonceSize], frame[:nonceSize], frame[nonceSize:], additionalData(r.header[:], r.index, flags))
+	if err != nil {
+		return ErrAuthFailed
+	}
+	r.plain = plain
+	r.index++
+	r.final = flags&flagFinal != 0
+	return nil
+}
+
+func (r *reader) readHeader() error {
+	if _, err := io.ReadFull(r.r, r.header[:]); err != nil {
+		return truncated(err)
+	}
+	if string(r.header[:4]) != magic {
+		return ErrAuthFailed
+	}
+	frameSize := int(binary.BigEndian.Uint32(r.header[4:]))
+	if frameSize < 1 || frameSize > MaxFrameSize {
+		return ErrAuthFailed
+	}
+	r.maxLen = frameSize + r.aead.Overhead()
+	return nil
+}
+
+func additionalData(header []byte, index uint64, flags byte) []byte {
+	ad := make([]byte, 0, additionalSize)
+	ad = append(ad, header...)
+	ad = binary.BigEndian.AppendUint64(ad, index)
+	return append(ad, flags)
+}
+
+// truncated maps a short read to ErrAuthFailed; other errors pass through
+func truncated(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrAuthFailed
+	}
+	return err
+}
@@ -0,0 +1,358 @@
+// Package dedup provides a storage backend that stores identical data
+// only once.
+//
+// Data is written to a temporary location of the Locator first. When the
+// writer is closed, its SHA-256 hash is computed, and the data is moved to
+// the location "blob-<hash>" unless a blob with the same hash is already
+// stored, in which case the temporary data is dropped. The logical name
+// refers to the blob through a reference record "ref-<name>" holding the
+// hash, and "refs-<hash>" counts the references. Remove deletes the blob
+// once the last reference is gone. All records are plain text, so they
+// can be inspected with other tools.
+//
+// Reference counting is serialized per Locator and hash within the
+// process, so concurrent writers of identical data are safe as long as all
+// backends sharing a namespace live in the same process and are created
+// from the same Locator value. Backends of unrelated locators never wait
+// for each other.
+package dedup
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+// maxRecordSize bounds how much is read from a reference or count record
+const maxRecordSize = 128
+
+var errClosed = errors.New("dedup: use of closed writer")
+
+type backend struct {
+	locator storage.Locator
+	scope   any
+	name    string
+}
+
+// Wrap returns a backend that deduplicates data stored in locations of
+// locator by content hash
+func Wrap(locator storage.Locator, opts ...Option) storage.Backend {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.name == "" {
+		o.name = randomName()
+	}
+	return &backend{locator: locator, scope: lockScope(locator), name: o.name}
+}
+
+func randomName() string {
+	var buf [16]byte
+	rand.Read(buf[:])
+	return hex.EncodeToString(buf[:])
+}
+
+func (b *backend) ref() storage.Backend {
+	return b.locator.Location("ref-" + b.name)
+}
+
+func (b *backend) blob(sum string) storage.Backend {
+	return b.locator.Location("blob-" + sum)
+}
+
+func (b *backend) refs(sum string) storage.Backend {
+	return b.locator.Location("refs-" + sum)
+}
+
+// Create returns a writer that stores the data under its hash on Close.
+// Data stored under the logical name before is released once the new
+// data has been committed.
+func (b *backend) Create() (io.WriteCloser, error) {
+	tmp := b.locator.Location("tmp-" + randomName())
+	w, err := tmp.Create()
+	if err != nil {
+		return nil, fmt.Errorf("dedup: create temp: %w", err)
+	}
+	return &writer{b: b, tmp: tmp, w: w, h: sha256.New()}, nil
+}
+
+// Open follows the reference record and opens the blob it points to
+func (b *backend) Open() (io.ReadCloser, error) {
+	sum, err := b.readRef()
+	if err != nil {
+		return nil, err
+	}
+	r, err := b.blob(sum).Open()
+	if err != nil {
+		return nil, fmt.Errorf("dedup: open blob %s: %w", sum, err)
+	}
+	return r, nil
+}
+
+// Remove drops the reference record and deletes the blob if no other
+// reference is left. If the blob cannot be released, the reference record
+// is restored so that a later Remove can try again. Removing a missing
+// location is a no-op.
+func (b *backend) Remove() error {
+	sum, err := b.readRef()
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := b.ref().Remove(); err != nil {
+		return fmt.Errorf("dedup: remove reference: %w", err)
+	}
+	if err := b.release(sum); err != nil {
+		if rerr := writeRecord(b.ref(), sum); rerr != nil {
+			err = errors.Join(err, fmt.Errorf("dedup: restore reference: %w", rerr))
+		}
+		return err
+	}
+	return nil
+}
+
+// Exists reports whether the name refers to a blob
+func (b *backend) Exists() (bool, error) {
+	_, err := b.readRef()
+	if errors.Is(err, storage.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// commit stores the data of tmp with hash sum and points the logical name
+// at it
+func (b *backend) commit(tmp storage.Backend, sum string) error {
+	old, err := b.readRef()
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+
+	if err := b.acquire(tmp, sum); err != nil {
+		return err
+	}
+	if old == sum {
+		// The name already refers to this data; keep a single reference
+		return b.release(sum)
+	}
+	if err := writeRecord(b.ref(), sum); err != nil {
+		b.release(sum)
+		return fmt.Errorf("dedup: write reference: %w", err)
+	}
+	if old != "" {
+		return b.release(old)
+	}
+	return nil
+}
+
+// acquire adds a reference to the blob sum, moving the data of tmp into
+// place if the blob does not exist yet, and removes tmp otherwise
+func (b *backend) acquire(tmp storage.Backend, sum string) error {
+	unlock := locks.lock(lockKey{b.scope, sum})
+	defer unlock()
+
+	count, err := b.readCount(sum)
+	if err != nil {
+		tmp.Remove()
+		return err
+	}
+	blob := b.blob(sum)
+	ok, err := storage.Exists(blob)
+	if err != nil {
+		tmp.Remove()
+		return fmt.Errorf("dedup: probe blob %s: %w", sum, err)
+	}
+
+	if count == 0 || !ok {
+		if _, err := storage.Move(blob, tmp); err != nil {
+			tmp.Remove()
+			return fmt.Errorf("dedup: store blob %s: %w", sum, err)
+		}
+		count = 0
+	} else if err := tmp.Remove(); err != nil {
+		return fmt.Errorf("dedup: remove temp: %w", err)
+	}
+
+	if err := writeRecord(b.refs(sum), strconv.FormatInt(count+1, 10)); err != nil {
+		return fmt.Errorf("dedup: write reference count: %w", err)
+	}
+	return nil
+}
+
+// release drops a reference to the blob sum and deletes it with the last one
+func (b *backend) release(sum string) error {
+	unlock := locks.lock(lockKey{b.scope, sum})
+	defer unlock()
+
+	count, err := b.readCount(sum)
+	if err != nil {
+		return err
+	}
+	if count > 1 {
+		if err := writeRecord(b.refs(sum), strconv.FormatInt(count-1, 10)); err != nil {
+			return fmt.Errorf("dedup: write reference count: %w", err)
+		}
+		return nil
+	}
+
+	if err := b.blob(sum).Remove(); err != nil {
+		return fmt.Errorf("dedup: remove blob %s: %w", sum, err)
+	}
+	if err := b.refs(sum).Remove(); err != nil {
+		return fmt.Errorf("dedup: remove reference count: %w", err)
+	}
+	return nil
+}
+
+func (b *backend) readRef() (string, error) {
+	sum, err := readRecord(b.ref())
+	if err != nil {
+		return "", fmt.Errorf("dedup: read reference: %w", err)
+	}
+	return sum, nil
+}
+
+// readCount returns the reference count of sum, or zero if it has none
+func (b *backend) readCount(sum string) (int64, error) {
+	s, err := readRecord(b.refs(sum))
+	if errors.Is(err, storage.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("dedup: read reference count: %w", err)
+	}
+	count, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || count < 0 {
+		return 0, fmt.Errorf("dedup: invalid reference count %q for %s", s, sum)
+	}
+	return count, nil
+}
+
+func readRecord(b storage.Backend) (string, error) {
+	r, err := b.Open()
+	if err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxRecordSize))
+	if err := errors.Join(err, r.Close()); err != nil {
+		return "", err
+	}
+	return string(bytes.TrimSpace(data)), nil
+}
+
+func writeRecord(b storage.Backend, s string) error {
+	w, err := b.Create()
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, s)
+	return errors.Join(err, w.Close())
+}
+
+// writer hashes the data while writing it to a temporary location
+type writer struct {
+	b      *backend
+	tmp    storage.Backend
+	w      io.WriteCloser
+	h      hash.Hash
+	err    error
+	closed bool
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errClosed
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.w.Write(p)
+	w.h.Write(p[:n])
+	if err != nil {
+		w.err = err
+	}
+	return n, err
+}
+
+// Close commits the data under its hash. After a failed write the
+// temporary data is removed instead.
+func (w *writer) Close() error {
+	if w.closed {
+		return w.err
+	}
+	w.closed = true
+
+	if err := w.w.Close(); err != nil && w.err == nil {
+		w.err = err
+	}
+	if w.err != nil {
+		w.tmp.Remove()
+		return w.err
+	}
+	w.err = w.b.commit(w.tmp, hex.EncodeToString(w.h.Sum(nil)))
+	return w.err
+}
+
+// locks serializes reference counting per locator and hash
+var locks = keyedMutex{m: make(map[lockKey]*keyedEntry)}
+
+// lockKey identifies a hash within the namespace of a locator
+type lockKey struct {
+	scope any
+	sum   string
+}
+
+// lockScope returns the value that scopes the locks of l. Locators whose
+// type cannot be used as a map key share a single process-wide scope.
+func lockScope(l storage.Locator) any {
+	if reflect.TypeOf(l).Comparable() {
+		return l
+	}
+	return nil
+}
+
+type keyedMutex struct {
+	mu sync.Mutex
+	m  map[lockKey]*keyedEntry
+}
+
+type keyedEntry struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock locks key and returns the function unlocking it
+func (k *keyedMutex) lock(key lockKey) func() {
+	k.mu.Lock()
+	e := k.m[key]
+	if e == nil {
+		e = &keyedEntry{}
+		k.m[key] = e
+	}
+	e.refs++
+	k.mu.Unlock()
+
+	e.mu.Lock()
+	return func() {
+		e.mu.Unlock()
+		k.mu.Lock()
+		e.refs--
+		if e.refs == 0 {
+			delete(k.m, key)
+		}
+		k.mu.Unlock()
+	}
+}
@@ -0,0 +1,202 @@
+package dedup_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/dedup"
+	"schneider.vip/hybridbuffer/storage/memory"
+	"schneider.vip/hybridbuffer/storage/storagetest"
+)
+
+func TestSuite(t *testing.T) {
+	l := memory.NewLocator()
+	storagetest.RunSuite(t, func() storage.Backend {
+		return dedup.Wrap(l)
+	})
+}
+
+func TestSharedBlob(t *testing.T) {
+	l := memory.NewLocator()
+	a := dedup.Wrap(l, dedup.WithName("a"))
+	b := dedup.Wrap(l, dedup.WithName("b"))
+	write(t, a, "same data")
+	write(t, b, "same data")
+
+	blob := l.Location("blob-" + sum("same data"))
+	if got := read(t, blob); got != "same data" {
+		t.Fatalf("blob holds %q, want %q", got, "same data")
+	}
+	if got := read(t, l.Location("refs-"+sum("same data"))); got != "2" {
+		t.Fatalf("reference count %q, want 2", got)
+	}
+
+	// The blob stays until the last reference is removed
+	if err := a.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(t, b); got != "same data" {
+		t.Fatalf("got %q after removing the other reference", got)
+	}
+	mustExist(t, blob, true)
+
+	if err := b.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	mustExist(t, blob, false)
+	mustExist(t, l.Location("refs-"+sum("same data")), false)
+}
+
+func TestOverwriteReleasesOldBlob(t *testing.T) {
+	l := memory.NewLocator()
+	a := dedup.Wrap(l, dedup.WithName("a"))
+	write(t, a, "old")
+	write(t, a, "old")
+	if got := read(t, l.Location("refs-"+sum("old"))); got != "1" {
+		t.Fatalf("rewriting the same data: reference count %q, want 1", got)
+	}
+
+	write(t, a, "new")
+	mustExist(t, l.Location("blob-"+sum("old")), false)
+	if got := read(t, a); got != "new" {
+		t.Fatalf("got %q, want %q", got, "new")
+	}
+}
+
+func TestConcurrentIdenticalWrites(t *testing.T) {
+	l := memory.NewLocator()
+	const n = 20
+	backends := make([]storage.Backend, n)
+	var wg sync.WaitGroup
+	for i := range backends {
+		backends[i] = dedup.Wrap(l, dedup.WithName(fmt.Sprint(i)))
+		wg.Add(1)
+		go func(b storage.Backend) {
+			defer wg.Done()
+			w, err := b.Create()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			io.WriteString(w, "same data")
+			if err := w.Close(); err != nil {
+				t.Error(err)
+			}
+		}(backends[i])
+	}
+	wg.Wait()
+
+	if got := read(t, l.Location("refs-"+sum("same data"))); got != fmt.Sprint(n) {
+		t.Fatalf("reference count %q, want %d", got, n)
+	}
+	for _, b := range backends {
+		if got := read(t, b); got != "same data" {
+			t.Fatalf("got %q, want %q", got, "same data")
+		}
+		if err := b.Remove(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustExist(t, l.Location("blob-"+sum("same data")), false)
+}
+
+var errRemove = errors.New("remove failed")
+
+// faultyLocator fails Remove on blob locations while failBlobs is set
+type faultyLocator struct {
+	storage.Locator
+	failBlobs bool
+}
+
+func (l *faultyLocator) Location(name string) storage.Backend {
+	b := l.Locator.Location(name)
+	if strings.HasPrefix(name, "blob-") {
+		return &faultyBlob{Backend: b, l: l}
+	}
+	return b
+}
+
+type faultyBlob struct {
+	storage.Backend
+	l *faultyLocator
+}
+
+func (b *faultyBlob) Remove() error {
+	if b.l.failBlobs {
+		return errRemove
+	}
+	return b.Backend.Remove()
+}
+
+func TestFailedReleaseKeepsReference(t *testing.T) {
+	l := &faultyLocator{Locator: memory.NewLocator()}
+	a := dedup.Wrap(l, dedup.WithName("a"))
+	write(t, a, "data")
+
+	l.failBlobs = true
+	if err := a.Remove(); !errors.Is(err, errRemove) {
+		t.Fatalf("Remove: got %v, want the blob's error", err)
+	}
+	if got := read(t, a); got != "data" {
+		t.Fatalf("got %q after the failed Remove", got)
+	}
+
+	l.failBlobs = false
+	if err := a.Remove(); err != nil {
+		t.Fatalf("retried Remove: %v", err)
+	}
+	mustExist(t, a, false)
+	mustExist(t, l.Location("blob-"+sum("data")), false)
+	mustExist(t, l.Location("refs-"+sum("data")), false)
+}
+
+func sum(data string) string {
+	h := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(h[:])
+}
+
+func mustExist(t *testing.T, b storage.Backend, want bool) {
+	t.Helper()
+	ok, err := storage.Exists(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok != want {
+		t.Fatalf("Exists: got %v, want %v", ok, want)
+	}
+}
+
+func write(t *testing.T, b storage.Backend, data string) {
+	t.Helper()
+	w, err := b.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func read(t *testing.T, b storage.Backend) string {
+	t.Helper()
+	r, err := b.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,17 @@
+package dedup
+
+// Option configures the deduplicating backend
+type Option func(*options)
+
+type options struct {
+	name string
+}
+
+// WithName sets the logical name of the backend's location. Backends
+// created with the same locator and name refer to the same data. Without
+// it a random name is generated.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
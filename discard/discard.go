@@ -0,0 +1,139 @@
+// Package discard provides a storage backend that throws away all data.
+//
+// It is meant for dry runs: a Counter records how much data would have
+// been written, so the backend can be wired into production temporarily
+// to plan the capacity of a real backend.
+//
+// Backend methods and the returned streams are safe for concurrent use.
+package discard
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+// Stats is a snapshot of the operations recorded by a Counter
+type Stats struct {
+	// Creates, Opens and Removes count the calls of the respective method
+	Creates int64
+	Opens   int64
+	Removes int64
+
+	// BytesWritten is the total number of bytes written to all writers
+	BytesWritten int64
+
+	// Active is the number of locations created and not yet removed
+	Active int64
+}
+
+// Counter aggregates the statistics of all backends created by it
+type Counter struct {
+	creates      atomic.Int64
+	opens        atomic.Int64
+	removes      atomic.Int64
+	bytesWritten atomic.Int64
+	active       atomic.Int64
+}
+
+// NewCounter returns a Counter with all statistics set to zero
+func NewCounter() *Counter {
+	return &Counter{}
+}
+
+// New returns a discarding backend that reports to c
+func (c *Counter) New() storage.Backend {
+	return &backend{c: c}
+}
+
+// Stats returns a snapshot of the recorded statistics
+func (c *Counter) Stats() Stats {
+	return Stats{
+		Creates:      c.creates.Load(),
+		Opens:        c.opens.Load(),
+		Removes:      c.removes.Load(),
+		BytesWritten: c.bytesWritten.Load(),
+		Active:       c.active.Load(),
+	}
+}
+
+type backend struct {
+	// c is nil for backends that do not record statistics
+	c *Counter
+
+	mu      sync.Mutex
+	created bool
+}
+
+// New returns a discarding backend that records no statistics
+func New() storage.Backend {
+	return &backend{}
+}
+
+// Create returns a writer that counts and discards all data
+func (b *backend) Create() (io.WriteCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.c != nil {
+		b.c.creates.Add(1)
+		if !b.created {
+			b.c.active.Add(1)
+		}
+	}
+	b.created = true
+	return &writer{c: b.c}, nil
+}
+
+// Open returns an empty reader once Create has been called
+func (b *backend) Open() (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.c != nil {
+		b.c.opens.Add(1)
+	}
+	if !b.created {
+		return nil, fmt.Errorf("discard: open: %w", storage.ErrNotFound)
+	}
+	return io.NopCloser(eofReader{}), nil
+}
+
+// Remove forgets that the location has been created
+func (b *backend) Remove() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.c != nil {
+		b.c.removes.Add(1)
+		if b.created {
+			b.c.active.Add(-1)
+		}
+	}
+	b.created = false
+	return nil
+}
+
+type writer struct {
+	c *Counter
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.c != nil {
+		w.c.bytesWritten.Add(int64(len(p)))
+	}
+	return len(p), nil
+}
+
+func (w *writer) Close() error {
+	return nil
+}
+
+type eofReader struct{}
+
+func (eofReader) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
@@ -0,0 +1,56 @@
+package discard_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/discard"
+)
+
+// TestSuite checks the parts of the storagetest contract that apply to a
+// backend throwing its data away; RunSuite itself expects a round trip.
+func TestSuite(t *testing.T) {
+	c := discard.NewCounter()
+	b := c.New()
+
+	if _, err := b.Open(); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("Open before Create: got %v, want ErrNotFound", err)
+	}
+
+	w, err := b.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := b.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(r)
+	r.Close()
+	if err != nil || len(data) != 0 {
+		t.Fatalf("Open after Create: got %q, %v, want no data", data, err)
+	}
+
+	for range 2 {
+		if err := b.Remove(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := b.Open(); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("Open after Remove: got %v, want ErrNotFound", err)
+	}
+
+	want := discard.Stats{Creates: 1, Opens: 3, Removes: 2, BytesWritten: 5}
+	if got := c.Stats(); got != want {
+		t.Fatalf("Stats: got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,13 @@
+package discard
+
+import (
+	"net/url"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+func init() {
+	storage.Register("discard", func(*url.URL) (storage.Backend, error) {
+		return New(), nil
+	})
+}
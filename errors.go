@@ -0,0 +1,48 @@
+package storage
+
+import "errors"
+
+// Sentinel errors for common failure modes. Backends wrap them so callers
+// can test for a failure mode independently of the implementation:
+//
+//	return fmt.Errorf("mybackend: open %s: %w", name, storage.ErrNotFound)
+//
+//	if errors.Is(err, storage.ErrNotFound) { ... }
+var (
+	// ErrNotFound is returned when a storage location does not exist
+	ErrNotFound = errors.New("storage: location not found")
+
+	// ErrAlreadyExists is returned when a storage location exists but
+	// must not
+	ErrAlreadyExists = errors.New("storage: location already exists")
+
+	// ErrPermission is returned when access to a storage location is denied
+	ErrPermission = errors.New("storage: permission denied")
+
+	// ErrUnavailable is returned when the backend cannot be reached or is
+	// temporarily unable to serve requests
+	ErrUnavailable = errors.New("storage: backend unavailable")
+)
+
+// ErrNotSeekable is returned when a backend cannot open a seekable reader
+var ErrNotSeekable = errors.New("storage: backend does not support seeking")
+
+// IsNotFound reports whether err wraps ErrNotFound
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsAlreadyExists reports whether err wraps ErrAlreadyExists
+func IsAlreadyExists(err error) bool {
+	return errors.Is(err, ErrAlreadyExists)
+}
+
+// IsPermission reports whether err wraps ErrPermission
+func IsPermission(err error) bool {
+	return errors.Is(err, ErrPermission)
+}
+
+// IsUnavailable reports whether err wraps ErrUnavailable
+func IsUnavailable(err error) bool {
+	return errors.Is(err, ErrUnavailable)
+}
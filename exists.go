@@ -0,0 +1,30 @@
+package storage
+
+import "errors"
+
+// Exister is implemented by backends that can cheaply probe whether
+// the storage location exists
+type Exister interface {
+	// Exists reports whether the location exists. A missing location
+	// is reported as (false, nil), not as an error.
+	Exists() (bool, error)
+}
+
+// Exists reports whether the storage location of b exists.
+// If b does not implement Exister, the location is opened and closed
+// immediately; an error wrapping ErrNotFound is reported as false.
+func Exists(b Backend) (bool, error) {
+	if e, ok := b.(Exister); ok {
+		return e.Exists()
+	}
+
+	r, err := b.Open()
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	r.Close()
+	return true, nil
+}
@@ -0,0 +1,79 @@
+package filesystem
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CleanupStale removes uncommitted temp files left in dir by backends
+// created with New, e.g. after the process was killed mid-write. Only
+// regular files matching the default naming scheme, which is the prefix
+// option followed by a random hex suffix and ".tmp", whose modification
+// time is older than olderThan are removed. Files named by WithNamer are
+// never matched. It returns the number of removed files.
+//
+// Pass the same prefix option that is used with New. CleanupStale is
+// meant to be called once at startup, before new spills are written.
+func CleanupStale(dir string, olderThan time.Duration, opts ...Option) (int, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, mapError(err)
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	removed := 0
+	var errs []error
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !isTempName(e.Name(), o.prefix) {
+			continue
+		}
+		info, err := e.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, mapError(err))
+			continue
+		}
+		if !info.ModTime().Before(cutoff) {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(dir, e.Name()))
+		switch {
+		case err == nil:
+			removed++
+		case !errors.Is(err, fs.ErrNotExist):
+			errs = append(errs, mapError(err))
+		}
+	}
+	return removed, errors.Join(errs...)
+}
+
+// isTempName reports whether name is a generated name of an uncommitted
+// file, i.e. is prefix, a random hex part and tmpSuffix
+func isTempName(name, prefix string) bool {
+	rest, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return false
+	}
+	rest, ok = strings.CutSuffix(rest, tmpSuffix)
+	if !ok || rest == "" {
+		return false
+	}
+	for i := 0; i < len(rest); i++ {
+		if c := rest[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package filesystem_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"schneider.vip/hybridbuffer/storage/filesystem"
+)
+
+func touch(t *testing.T, path string, age time.Duration) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Now().Add(-age)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func exists(t *testing.T, path string) bool {
+	t.Helper()
+	_, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return true
+}
+
+func TestCleanupStale(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]struct {
+		age     time.Duration
+		removed bool
+	}{
+		"hybridbuffer-0123456789abcdef.tmp": {2 * time.Hour, true},
+		"hybridbuffer-fedcba.tmp":           {time.Minute, false},   // fresh
+		"hybridbuffer-0123456789abcdef":     {2 * time.Hour, false}, // committed
+		"hybridbuffer-ABCDEF.tmp":           {2 * time.Hour, false}, // not lower-case hex
+		"hybridbuffer-.tmp":                 {2 * time.Hour, false}, // no random part
+		"hybridbuffer-spill.tmp":            {2 * time.Hour, false}, // named by WithNamer
+		"other-0123.tmp":                    {2 * time.Hour, false}, // other prefix
+	}
+	for name, f := range files {
+		touch(t, filepath.Join(dir, name), f.age)
+	}
+	// Directories are never removed, even with a matching name
+	sub := filepath.Join(dir, "hybridbuffer-abcd.tmp")
+	if err := os.Mkdir(sub, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	os.Chtimes(sub, old, old)
+
+	n, err := filesystem.CleanupStale(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("removed %d files, want 1", n)
+	}
+	for name, f := range files {
+		if exists(t, filepath.Join(dir, name)) == f.removed {
+			t.Errorf("%s: removed = %v, want %v", name, !f.removed, f.removed)
+		}
+	}
+	if !exists(t, sub) {
+		t.Error("directory was removed")
+	}
+}
+
+func TestCleanupStalePrefix(t *testing.T) {
+	dir := t.TempDir()
+	touch(t, filepath.Join(dir, "spill-abcd.tmp"), 2*time.Hour)
+	touch(t, filepath.Join(dir, "hybridbuffer-abcd.tmp"), 2*time.Hour)
+
+	n, err := filesystem.CleanupStale(dir, time.Hour, filesystem.WithPrefix("spill-"))
+	if err != nil || n != 1 {
+		t.Fatalf("CleanupStale = %d, %v, want 1 removed file", n, err)
+	}
+	if exists(t, filepath.Join(dir, "spill-abcd.tmp")) {
+		t.Error("stale file with the given prefix was kept")
+	}
+	if !exists(t, filepath.Join(dir, "hybridbuffer-abcd.tmp")) {
+		t.Error("file with the default prefix was removed")
+	}
+}
+
+// TestCleanupStaleAbandonedWrite removes the temp file of a writer that
+// was never closed
+func TestCleanupStaleAbandonedWrite(t *testing.T) {
+	dir := t.TempDir()
+	w, err := filesystem.New(dir, filesystem.WithAtomicCommit(true)).Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(w, "partial")
+	defer w.Close()
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	if len(matches) != 1 {
+		t.Fatalf("found temp files %v, want one", matches)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(matches[0], old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := filesystem.CleanupStale(dir, time.Hour)
+	if err != nil || n != 1 {
+		t.Fatalf("CleanupStale = %d, %v, want 1 removed file", n, err)
+	}
+	if exists(t, matches[0]) {
+		t.Fatal("temp file of the abandoned write was kept")
+	}
+}
+
+func TestCleanupStaleMissingDir(t *testing.T) {
+	_, err := filesystem.CleanupStale(filepath.Join(t.TempDir(), "missing"), time.Hour)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("got %v, want an error wrapping fs.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,148 @@
+package filesystem_test
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/filesystem"
+	"schneider.vip/hybridbuffer/storage/storagetest"
+)
+
+func newFile(t testing.TB, dir string, data []byte) storage.Backend {
+	t.Helper()
+	b := filesystem.New(dir)
+	w, err := b.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Remove() })
+	return b
+}
+
+// allocated returns the number of heap bytes allocated by f
+func allocated(f func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
+
+// TestCopyFastPath checks that a copy between two files is handed to the
+// kernel: io.Copy would allocate a 32 KiB buffer otherwise
+func TestCopyFastPath(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("kernel file copies are only guaranteed on Linux")
+	}
+	dir := t.TempDir()
+	data := bytes.Repeat([]byte("hybridbuffer"), 1<<20/12)
+	src := newFile(t, dir, data)
+
+	copyTo := func(dst, src storage.Backend) uint64 {
+		t.Helper()
+		defer dst.Remove()
+		var err error
+		n := allocated(func() { _, err = storage.Copy(dst, src) })
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	if n := copyTo(storagetest.Plain(filesystem.New(dir)), storagetest.Plain(src)); n < 32<<10 {
+		t.Fatalf("buffered copy allocated only %d bytes; the measurement is broken", n)
+	}
+	if n := copyTo(filesystem.New(dir), src); n >= 32<<10 {
+		t.Fatalf("copy allocated %d bytes; the fast path was not taken", n)
+	}
+}
+
+func TestStatsThroughCopy(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 100<<10)
+	size := int64(len(data))
+	tests := []struct {
+		name          string
+		run           func(dst, src storage.Backend) error
+		read, written int64
+	}{
+		{"FileToFile", func(dst, src storage.Backend) error {
+			_, err := storage.Copy(dst, src)
+			return err
+		}, size, size},
+		// The reader's WriteTo writing to a foreign writer
+		{"WriteTo", func(_, src storage.Backend) error {
+			r, err := src.Open()
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+			_, err = r.(io.WriterTo).WriteTo(io.Discard)
+			return err
+		}, size, 0},
+		// The writer's ReadFrom reading from a foreign reader
+		{"ReadFrom", func(dst, _ storage.Backend) error {
+			w, err := dst.Create()
+			if err != nil {
+				return err
+			}
+			if _, err := w.(io.ReaderFrom).ReadFrom(bytes.NewReader(data)); err != nil {
+				return err
+			}
+			return w.Close()
+		}, 0, size},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := newFile(t, dir, data)
+			dst := filesystem.New(dir)
+			before, _ := storage.GetStats(src)
+			if err := tt.run(dst, src); err != nil {
+				t.Fatal(err)
+			}
+			srcStats, _ := storage.GetStats(src)
+			dstStats, _ := storage.GetStats(dst)
+
+			if n := srcStats.BytesRead - before.BytesRead; n != tt.read {
+				t.Errorf("source counted %d bytes read, want %d", n, tt.read)
+			}
+			if dstStats.BytesWritten != tt.written {
+				t.Errorf("destination counted %d bytes written, want %d", dstStats.BytesWritten, tt.written)
+			}
+			if srcStats.Errors != 0 || dstStats.Errors != 0 {
+				t.Errorf("errors counted: source %d, destination %d", srcStats.Errors, dstStats.Errors)
+			}
+		})
+	}
+}
+
+func BenchmarkCopy(b *testing.B) {
+	data := bytes.Repeat([]byte("hybridbuffer"), 4<<20/12)
+	dir := b.TempDir()
+	src := newFile(b, dir, data)
+
+	run := func(b *testing.B, dst, src storage.Backend) {
+		b.Cleanup(func() { dst.Remove() })
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for b.Loop() {
+			if _, err := storage.Copy(dst, src); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.Run("FastPath", func(b *testing.B) {
+		run(b, filesystem.New(dir), src)
+	})
+	b.Run("Buffered", func(b *testing.B) {
+		run(b, storagetest.Plain(filesystem.New(dir)), storagetest.Plain(src))
+	})
+}
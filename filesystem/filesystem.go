@@ -0,0 +1,280 @@
+// Package filesystem provides a storage backend that keeps data in a
+// temporary file on the local filesystem.
+//
+// The file name is chosen when the backend is constructed, so Open and
+// Remove always address the same file regardless of how often Create is
+// called. The first Create claims the name exclusively; if another file
+// already uses it, a new name is generated and Create retries.
+//
+// By default data is written to a sibling file with a ".tmp" suffix that
+// is atomically renamed to its final name when the writer is closed, so
+// a crash mid-write never leaves a truncated file behind for Open.
+//
+// Backend methods are safe for concurrent use. With atomic commit, Open
+// may be called while a writer is open and reports ErrNotFound until the
+// writer has been closed.
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/internal/stats"
+)
+
+type backend struct {
+	dir  string
+	opts options
+
+	// fixed is set for backends minted by a Locator, whose name is the
+	// identity of the location and never changes
+	fixed bool
+
+	mu      sync.Mutex
+	path    string
+	tmpPath string
+	nameErr error
+
+	// claimed is set once Create has created the file, so that later
+	// calls overwrite it instead of treating it as a collision
+	claimed bool
+
+	stats stats.Counters
+}
+
+// tmpSuffix marks files that have not been committed yet
+const tmpSuffix = ".tmp"
+
+// maxNameAttempts bounds the number of names tried by Create
+const maxNameAttempts = 10
+
+// New creates a filesystem backend that stores its data in a uniquely
+// named file under dir. The name is generated immediately, see WithNamer;
+// the file is created on Create and unlinked on Remove.
+func New(dir string, opts ...Option) storage.Backend {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	b := &backend{dir: dir, opts: o}
+	b.setNameLocked(o.name())
+	return b
+}
+
+// setNameLocked points the backend at the file name inside dir
+func (b *backend) setNameLocked(name string) {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		b.nameErr = fmt.Errorf("filesystem: invalid file name %q", name)
+		return
+	}
+	b.nameErr = nil
+	b.path = filepath.Join(b.dir, name)
+	b.tmpPath = ""
+	if b.opts.atomic {
+		b.tmpPath = b.path + tmpSuffix
+	}
+}
+
+// Create creates the file and returns a writer for it. With atomic commit
+// the data only becomes visible to Open once the writer is closed. Data
+// from a previous Create is replaced.
+func (b *backend) Create() (io.WriteCloser, error) {
+	b.stats.Creates.Add(1)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	f, err := b.createFileLocked()
+	if err != nil {
+		return nil, b.stats.Count(err)
+	}
+
+	w := &writer{File: f, fsync: b.opts.fsync, stats: &b.stats}
+	if b.opts.atomic {
+		w.commitPath = b.path
+	}
+	return w, nil
+}
+
+// createFileLocked creates the file written by Create. The first Create
+// of a generated name uses O_EXCL and picks a new name on collision.
+func (b *backend) createFileLocked() (*os.File, error) {
+	if b.nameErr != nil {
+		return nil, b.nameErr
+	}
+	if b.fixed || b.claimed {
+		return b.openFileLocked(os.O_TRUNC)
+	}
+
+	for attempt := 1; ; attempt++ {
+		f, err := b.claimLocked()
+		if err == nil {
+			b.claimed = true
+			return f, nil
+		}
+		if !errors.Is(err, storage.ErrAlreadyExists) || attempt >= maxNameAttempts {
+			return nil, err
+		}
+		b.setNameLocked(b.opts.name())
+		if b.nameErr != nil {
+			return nil, b.nameErr
+		}
+	}
+}
+
+// claimLocked exclusively creates the file for a name not used before.
+// With atomic commit the final name must not exist either.
+func (b *backend) claimLocked() (*os.File, error) {
+	if b.opts.atomic {
+		if _, err := os.Lstat(b.path); err == nil {
+			return nil, fmt.Errorf("filesystem: %w: %s", storage.ErrAlreadyExists, b.path)
+		}
+	}
+	return b.openFileLocked(os.O_EXCL)
+}
+
+// openFileLocked opens the file written by Create with the extra flag
+func (b *backend) openFileLocked(flag int) (*os.File, error) {
+	name := b.path
+	if b.opts.atomic {
+		name = b.tmpPath
+	}
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|flag, b.opts.perm)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	// Apply the mode regardless of the umask
+	if err := f.Chmod(b.opts.perm); err != nil {
+		f.Close()
+		return nil, mapError(err)
+	}
+	return f, nil
+}
+
+// Append opens the file for appending with O_APPEND, or creates it if
+// nothing has been written yet. Appended data bypasses atomic commit and
+// is visible to Open while it is being written.
+func (b *backend) Append() (io.WriteCloser, error) {
+	path, err := b.currentPath()
+	if err != nil {
+		return nil, b.stats.Count(err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if errors.Is(err, fs.ErrNotExist) {
+		return b.Create()
+	}
+	if err != nil {
+		return nil, b.stats.Count(mapError(err))
+	}
+	return &writer{File: f, fsync: b.opts.fsync, stats: &b.stats}, nil
+}
+
+// Open opens the file written by Create for reading
+func (b *backend) Open() (io.ReadCloser, error) {
+	b.stats.Opens.Add(1)
+	path, err := b.currentPath()
+	if err != nil {
+		return nil, b.stats.Count(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, b.stats.Count(mapError(err))
+	}
+	return &reader{File: f, stats: &b.stats}, nil
+}
+
+// Remove unlinks the file. Removing a file that does not exist is a no-op.
+func (b *backend) Remove() error {
+	b.stats.Removes.Add(1)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats.Count(b.removeLocked())
+}
+
+// Reset unlinks the file and, unless the backend was minted by a Locator,
+// generates a new name for the next Create. Calling Reset while a reader
+// or writer is open is undefined.
+func (b *backend) Reset() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.removeLocked(); err != nil {
+		return err
+	}
+	if !b.fixed {
+		b.setNameLocked(b.opts.name())
+	}
+	return nil
+}
+
+func (b *backend) removeLocked() error {
+	for _, path := range []string{b.tmpPath, b.path} {
+		if path == "" {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return mapError(err)
+		}
+	}
+	b.claimed = false
+	return nil
+}
+
+// Size returns the size of the file
+func (b *backend) Size() (int64, error) {
+	path, err := b.currentPath()
+	if err != nil {
+		return 0, err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, mapError(err)
+	}
+	return fi.Size(), nil
+}
+
+// Exists reports whether the file exists
+func (b *backend) Exists() (bool, error) {
+	path, err := b.currentPath()
+	if err != nil {
+		return false, err
+	}
+	if _, err = os.Stat(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, mapError(err)
+	}
+	return true, nil
+}
+
+func (b *backend) currentPath() (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.nameErr != nil {
+		return "", b.nameErr
+	}
+	return b.path, nil
+}
+
+// mapError wraps err and maps os errors onto the storage sentinel errors
+func mapError(err error) error {
+	var sentinel error
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		sentinel = storage.ErrNotFound
+	case errors.Is(err, fs.ErrExist):
+		sentinel = storage.ErrAlreadyExists
+	case errors.Is(err, fs.ErrPermission):
+		sentinel = storage.ErrPermission
+	default:
+		return fmt.Errorf("filesystem: %w", err)
+	}
+	return fmt.Errorf("filesystem: %w: %w", sentinel, err)
+}
@@ -0,0 +1,26 @@
+package filesystem_test
+
+import (
+	"fmt"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/filesystem"
+	"schneider.vip/hybridbuffer/storage/storagetest"
+)
+
+func TestSuite(t *testing.T) {
+	dir := t.TempDir()
+	storagetest.RunSuite(t, func() storage.Backend {
+		return filesystem.New(dir)
+	})
+}
+
+func TestSuiteLocator(t *testing.T) {
+	l := filesystem.NewLocator(t.TempDir())
+	var n int
+	storagetest.RunSuite(t, func() storage.Backend {
+		n++
+		return l.Location(fmt.Sprintf("spill-%d", n))
+	})
+}
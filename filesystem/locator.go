@@ -0,0 +1,58 @@
+package filesystem
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+type locator struct {
+	dir  string
+	opts []Option
+}
+
+// NewLocator returns a Locator whose locations are files in dir. Names
+// are escaped so that they always map to a single file directly inside
+// dir and cannot traverse outside of it. The prefix option is ignored.
+func NewLocator(dir string, opts ...Option) storage.Locator {
+	return &locator{dir: dir, opts: opts}
+}
+
+// Location returns the backend for the file dir/name
+func (l *locator) Location(name string) storage.Backend {
+	o := defaultOptions()
+	for _, opt := range l.opts {
+		opt(&o)
+	}
+	path := filepath.Join(l.dir, sanitize(name))
+	return &backend{
+		dir:     l.dir,
+		opts:    o,
+		fixed:   true,
+		path:    path,
+		tmpPath: path + tmpSuffix,
+	}
+}
+
+// sanitize escapes every byte outside [A-Za-z0-9_-] as %XX. The mapping
+// is injective, so distinct names never share a file, and the result never
+// contains a path separator or equals "." or "..". Escaping dots also keeps
+// names from colliding with the temp-file suffix.
+func sanitize(name string) string {
+	if name == "" {
+		return "%"
+	}
+	var sb strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '_', c == '-':
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,78 @@
+package filesystem
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+)
+
+// Option configures the filesystem backend
+type Option func(*options)
+
+type options struct {
+	perm   os.FileMode
+	prefix string
+	namer  func() string
+	fsync  bool
+	atomic bool
+}
+
+func defaultOptions() options {
+	return options{
+		perm:   0o600,
+		prefix: "hybridbuffer-",
+		atomic: true,
+	}
+}
+
+// WithPermissions sets the file mode used for created files (default 0600)
+func WithPermissions(perm os.FileMode) Option {
+	return func(o *options) {
+		o.perm = perm
+	}
+}
+
+// WithPrefix sets the filename prefix used for created files
+func WithPrefix(prefix string) Option {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
+
+// WithNamer sets the function generating the file name used by New.
+// It returns a base name inside the directory and replaces the default
+// of the prefix followed by a random hex suffix. The function is called
+// again if the generated name is already taken.
+func WithNamer(namer func() string) Option {
+	return func(o *options) {
+		o.namer = namer
+	}
+}
+
+// WithFsync enables an fsync of the file before the writer is closed and,
+// with atomic commit, of the parent directory after the rename
+func WithFsync(enabled bool) Option {
+	return func(o *options) {
+		o.fsync = enabled
+	}
+}
+
+// WithAtomicCommit controls whether data is written to a ".tmp" sibling
+// and renamed on Close (default true). Disabling it lets Open read data
+// that is still being written.
+func WithAtomicCommit(enabled bool) Option {
+	return func(o *options) {
+		o.atomic = enabled
+	}
+}
+
+// name returns the file name to use, generated by the namer option or
+// from the prefix and 128 random bits
+func (o *options) name() string {
+	if o.namer != nil {
+		return o.namer()
+	}
+	var buf [16]byte
+	rand.Read(buf[:])
+	return o.prefix + hex.EncodeToString(buf[:])
+}
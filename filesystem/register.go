@@ -0,0 +1,53 @@
+package filesystem
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+func init() {
+	storage.Register("file", fromURL)
+}
+
+// fromURL creates a backend from file:///dir?prefix=p&perm=0640&fsync=true&atomic=false
+func fromURL(u *url.URL) (storage.Backend, error) {
+	dir := u.Path
+	if dir == "" {
+		dir = u.Opaque
+	}
+	if dir == "" {
+		dir = os.TempDir()
+	}
+
+	var opts []Option
+	q := u.Query()
+	if v := q.Get("prefix"); v != "" {
+		opts = append(opts, WithPrefix(v))
+	}
+	if v := q.Get("perm"); v != "" {
+		perm, err := strconv.ParseUint(v, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("filesystem: invalid perm %q: %w", v, err)
+		}
+		opts = append(opts, WithPermissions(os.FileMode(perm)))
+	}
+	if v := q.Get("fsync"); v != "" {
+		fsync, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("filesystem: invalid fsync %q: %w", v, err)
+		}
+		opts = append(opts, WithFsync(fsync))
+	}
+	if v := q.Get("atomic"); v != "" {
+		atomic, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("filesystem: invalid atomic %q: %w", v, err)
+		}
+		opts = append(opts, WithAtomicCommit(atomic))
+	}
+	return New(dir, opts...), nil
+}
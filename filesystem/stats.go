@@ -0,0 +1,42 @@
+package filesystem
+
+import (
+	"io"
+	"os"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/internal/stats"
+)
+
+// Stats returns the operation counters of the backend
+func (b *backend) Stats() storage.BackendStats {
+	return b.stats.Snapshot()
+}
+
+// reader counts the bytes read from the file
+type reader struct {
+	*os.File
+	stats *stats.Counters
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.File.Read(p)
+	r.stats.BytesRead.Add(int64(n))
+	return n, r.stats.Count(err)
+}
+
+func (r *reader) ReadAt(p []byte, off int64) (int, error) {
+	n, err := r.File.ReadAt(p, off)
+	r.stats.BytesRead.Add(int64(n))
+	return n, r.stats.Count(err)
+}
+
+// WriteTo copies the file to w, letting the kernel copy the data where supported
+func (r *reader) WriteTo(w io.Writer) (int64, error) {
+	if fw, ok := w.(*writer); ok {
+		return fw.ReadFrom(r)
+	}
+	n, err := r.File.WriteTo(w)
+	r.stats.BytesRead.Add(n)
+	return n, r.stats.Count(err)
+}
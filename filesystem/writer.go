@@ -0,0 +1,112 @@
+package filesystem
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"schneider.vip/hybridbuffer/storage/internal/stats"
+)
+
+// writer optionally syncs the file and commits it to its final name on Close
+type writer struct {
+	*os.File
+	fsync bool
+	stats *stats.Counters
+
+	// commitPath is the final name for atomic commit, empty if disabled
+	commitPath string
+}
+
+// ReadFrom copies src into the file. Between two files the kernel copies
+// the data directly, e.g. with copy_file_range on Linux.
+func (w *writer) ReadFrom(src io.Reader) (int64, error) {
+	if r, ok := src.(*reader); ok {
+		// Hand the file itself to the kernel copy
+		n, err := w.readFrom(r.File)
+		r.stats.BytesRead.Add(n)
+		return n, err
+	}
+	return w.readFrom(src)
+}
+
+func (w *writer) readFrom(src io.Reader) (int64, error) {
+	n, err := w.File.ReadFrom(src)
+	w.stats.BytesWritten.Add(n)
+	if err != nil {
+		return n, w.stats.Count(mapError(err))
+	}
+	return n, nil
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	n, err := w.File.Write(p)
+	w.stats.BytesWritten.Add(int64(n))
+	return n, w.stats.Count(err)
+}
+
+func (w *writer) WriteString(s string) (int, error) {
+	n, err := w.File.WriteString(s)
+	w.stats.BytesWritten.Add(int64(n))
+	return n, w.stats.Count(err)
+}
+
+// Sync flushes the data written so far to stable storage.
+// With atomic commit enabled the data only appears under its final name
+// once the writer is closed.
+func (w *writer) Sync() error {
+	if err := w.File.Sync(); err != nil {
+		return mapError(err)
+	}
+	return nil
+}
+
+func (w *writer) Close() error {
+	return w.stats.Count(w.close())
+}
+
+func (w *writer) close() error {
+	if w.fsync {
+		if err := w.File.Sync(); err != nil {
+			w.abort()
+			return mapError(err)
+		}
+	}
+	if err := w.File.Close(); err != nil {
+		w.abort()
+		return mapError(err)
+	}
+	if w.commitPath == "" {
+		return nil
+	}
+
+	if err := os.Rename(w.File.Name(), w.commitPath); err != nil {
+		os.Remove(w.File.Name())
+		return mapError(err)
+	}
+	if w.fsync {
+		return syncDir(filepath.Dir(w.commitPath))
+	}
+	return nil
+}
+
+// abort closes the file and drops uncommitted data
+func (w *writer) abort() {
+	w.File.Close()
+	if w.commitPath != "" {
+		os.Remove(w.File.Name())
+	}
+}
+
+// syncDir makes a rename in dir durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return mapError(err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return mapError(err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package gcs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/googleapi"
+
+	hybridstorage "schneider.vip/hybridbuffer/storage"
+)
+
+// mapError wraps err and maps GCS failures onto the storage sentinel errors
+func mapError(op string, err error) error {
+	if sentinel := classify(err); sentinel != nil {
+		return fmt.Errorf("gcs: %s: %w: %w", op, sentinel, err)
+	}
+	return fmt.Errorf("gcs: %s: %w", op, err)
+}
+
+func classify(err error) error {
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return hybridstorage.ErrNotFound
+	}
+
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return nil
+	}
+	switch {
+	case apiErr.Code == http.StatusNotFound:
+		return hybridstorage.ErrNotFound
+	case apiErr.Code == http.StatusForbidden, apiErr.Code == http.StatusUnauthorized:
+		return hybridstorage.ErrPermission
+	case apiErr.Code == http.StatusPreconditionFailed:
+		return hybridstorage.ErrAlreadyExists
+	case apiErr.Code == http.StatusTooManyRequests, apiErr.Code >= 500:
+		return hybridstorage.ErrUnavailable
+	}
+	return nil
+}
+
+// isInvalidRange reports whether err rejects a range starting beyond the
+// end of the object
+func isInvalidRange(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusRequestedRangeNotSatisfiable
+}
@@ -0,0 +1,152 @@
+// Package gcs provides a storage backend that keeps data in a Google
+// Cloud Storage object.
+//
+// Backend methods are safe for concurrent use. The object is only
+// replaced when a writer is closed, so Open during a write sees the
+// previous object, if any.
+package gcs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"sync"
+
+	"cloud.google.com/go/storage"
+
+	hybridstorage "schneider.vip/hybridbuffer/storage"
+)
+
+type backend struct {
+	obj  *storage.ObjectHandle
+	opts options
+
+	mu       sync.Mutex
+	metadata map[string]string
+}
+
+// New creates a GCS backend storing its data in object of bucket
+func New(client *storage.Client, bucket, object string, opts ...Option) hybridstorage.Backend {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &backend{obj: client.Bucket(bucket).Object(object), opts: o}
+}
+
+// SetMetadata sets the custom metadata stored with objects written by
+// subsequent Create calls
+func (b *backend) SetMetadata(md map[string]string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.metadata = maps.Clone(md)
+}
+
+// Create returns a writer that uploads the object
+func (b *backend) Create() (io.WriteCloser, error) {
+	return b.CreateContext(context.Background())
+}
+
+// CreateContext returns a writer that uploads the object. Cancelling ctx
+// before Close aborts the upload and leaves no object behind.
+func (b *backend) CreateContext(ctx context.Context) (io.WriteCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	w := b.obj.NewWriter(ctx)
+	if b.opts.chunkSet {
+		w.ChunkSize = b.opts.chunkSize
+	}
+	w.ContentType = b.opts.contentType
+	b.mu.Lock()
+	w.Metadata = b.metadata
+	b.mu.Unlock()
+	return &writer{w: w}, nil
+}
+
+// Open returns a reader for the object
+func (b *backend) Open() (io.ReadCloser, error) {
+	return b.OpenContext(context.Background())
+}
+
+// OpenContext returns a reader for the object bound to ctx
+func (b *backend) OpenContext(ctx context.Context) (io.ReadCloser, error) {
+	r, err := b.obj.NewReader(ctx)
+	if err != nil {
+		return nil, mapError("new reader", err)
+	}
+	return r, nil
+}
+
+// OpenRange returns a reader for length bytes of the object starting at
+// off. A length of -1 reads to the end of the object.
+func (b *backend) OpenRange(off, length int64) (io.ReadCloser, error) {
+	if off < 0 || length < -1 {
+		return nil, errors.New("gcs: open range: invalid range")
+	}
+	r, err := b.obj.NewRangeReader(context.Background(), off, length)
+	if err != nil {
+		if isInvalidRange(err) {
+			// The object exists but ends before off
+			return io.NopCloser(bytes.NewReader(nil)), nil
+		}
+		return nil, mapError("new range reader", err)
+	}
+	return r, nil
+}
+
+// Remove deletes the object. Deleting a missing object is a no-op.
+func (b *backend) Remove() error {
+	return b.RemoveContext(context.Background())
+}
+
+// RemoveContext deletes the object bound to ctx
+func (b *backend) RemoveContext(ctx context.Context) error {
+	if err := b.obj.Delete(ctx); err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+		return mapError("delete", err)
+	}
+	return nil
+}
+
+// Size returns the object size from its attributes
+func (b *backend) Size() (int64, error) {
+	attrs, err := b.obj.Attrs(context.Background())
+	if err != nil {
+		return 0, mapError("attrs", err)
+	}
+	return attrs.Size, nil
+}
+
+// Exists reports whether the object exists
+func (b *backend) Exists() (bool, error) {
+	_, err := b.obj.Attrs(context.Background())
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, mapError("attrs", err)
+	}
+	return true, nil
+}
+
+// writer maps upload errors, which GCS reports on Close at the latest
+type writer struct {
+	w *storage.Writer
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	if err != nil {
+		return n, mapError("write", err)
+	}
+	return n, nil
+}
+
+func (w *writer) Close() error {
+	if err := w.w.Close(); err != nil {
+		return mapError("upload", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package gcs
+
+// Option configures the GCS backend
+type Option func(*options)
+
+type options struct {
+	chunkSize   int
+	chunkSet    bool
+	contentType string
+}
+
+// WithChunkSize sets the chunk size of resumable uploads. Each chunk is
+// buffered in memory; zero disables chunking and uploads in a single request.
+func WithChunkSize(size int) Option {
+	return func(o *options) {
+		o.chunkSize = size
+		o.chunkSet = true
+	}
+}
+
+// WithContentType sets the Content-Type of uploaded objects. Without it
+// GCS detects the type from the data.
+func WithContentType(contentType string) Option {
+	return func(o *options) {
+		o.contentType = contentType
+	}
+}
@@ -0,0 +1,52 @@
+package httpbackend
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+// StatusError is returned for responses with an unexpected status code
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s: unexpected status %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// mapError wraps err and maps HTTP failures onto the storage sentinel errors
+func mapError(op string, err error) error {
+	if sentinel := classify(err); sentinel != nil {
+		return fmt.Errorf("httpbackend: %s: %w: %w", op, sentinel, err)
+	}
+	return fmt.Errorf("httpbackend: %s: %w", op, err)
+}
+
+func classify(err error) error {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch code := statusErr.StatusCode; {
+		case code == http.StatusNotFound, code == http.StatusGone:
+			return storage.ErrNotFound
+		case code == http.StatusUnauthorized, code == http.StatusForbidden:
+			return storage.ErrPermission
+		case code == http.StatusConflict, code == http.StatusPreconditionFailed:
+			return storage.ErrAlreadyExists
+		case code == http.StatusTooManyRequests, code >= 500:
+			return storage.ErrUnavailable
+		}
+		return nil
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return storage.ErrUnavailable
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+// Package httpbackend provides a storage backend that keeps data at a URL
+// of a plain HTTP blob service: Create uploads with PUT, Open downloads
+// with GET and Remove sends DELETE.
+//
+// Uploads are streamed through an io.Pipe, so the payload is never
+// buffered as a whole. Whether Open during a write sees the previous data
+// depends on the service.
+package httpbackend
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+type backend struct {
+	client *http.Client
+	url    string
+	opts   options
+}
+
+// New creates an HTTP backend storing its data at url. A nil client is
+// treated as http.DefaultClient.
+func New(client *http.Client, url string, opts ...Option) storage.Backend {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &backend{client: client, url: url, opts: o}
+}
+
+// Create returns a writer streaming a PUT request body
+func (b *backend) Create() (io.WriteCloser, error) {
+	return b.CreateContext(context.Background())
+}
+
+// CreateContext returns a writer streaming a PUT request body bound to
+// ctx. The upload completes when the writer is closed.
+func (b *backend) CreateContext(ctx context.Context) (io.WriteCloser, error) {
+	pr, pw := io.Pipe()
+	req, err := b.newRequest(ctx, http.MethodPut, pr)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &writer{pw: pw, done: make(chan error, 1)}
+	go func() {
+		err := b.do(req, http.StatusOK, http.StatusCreated, http.StatusNoContent)
+		// Unblock pending writes if the request ended early
+		pr.CloseWithError(errors.Join(err, errUploadEnded))
+		w.done <- err
+	}()
+	return w, nil
+}
+
+// Open issues a GET request and returns the response body
+func (b *backend) Open() (io.ReadCloser, error) {
+	return b.OpenContext(context.Background())
+}
+
+// OpenContext issues a GET request bound to ctx and returns the response body
+func (b *backend) OpenContext(ctx context.Context) (io.ReadCloser, error) {
+	req, err := b.newRequest(ctx, http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, mapError("get", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		discard(resp)
+		return nil, mapError("get", b.statusError(req, resp))
+	}
+	return resp.Body, nil
+}
+
+// Remove sends a DELETE request. Deleting a missing resource is a no-op.
+func (b *backend) Remove() error {
+	return b.RemoveContext(context.Background())
+}
+
+// RemoveContext sends a DELETE request bound to ctx
+func (b *backend) RemoveContext(ctx context.Context) error {
+	req, err := b.newRequest(ctx, http.MethodDelete, nil)
+	if err != nil {
+		return err
+	}
+	return b.do(req, http.StatusOK, http.StatusAccepted, http.StatusNoContent, http.StatusNotFound)
+}
+
+func (b *backend) newRequest(ctx context.Context, method string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, b.url, body)
+	if err != nil {
+		return nil, mapError("new request", err)
+	}
+	for k, v := range b.opts.header {
+		req.Header[k] = v
+	}
+	return req, nil
+}
+
+// do sends req and fails unless the response has one of the ok statuses
+func (b *backend) do(req *http.Request, ok ...int) error {
+	op := httpOp(req.Method)
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return mapError(op, err)
+	}
+	defer discard(resp)
+	for _, code := range ok {
+		if resp.StatusCode == code {
+			return nil
+		}
+	}
+	return mapError(op, b.statusError(req, resp))
+}
+
+func (b *backend) statusError(req *http.Request, resp *http.Response) error {
+	return &StatusError{Method: req.Method, URL: b.url, StatusCode: resp.StatusCode}
+}
+
+func httpOp(method string) string {
+	switch method {
+	case http.MethodPut:
+		return "put"
+	case http.MethodDelete:
+		return "delete"
+	}
+	return "get"
+}
+
+// discard drains a little of the body so the connection can be reused
+func discard(resp *http.Response) {
+	io.CopyN(io.Discard, resp.Body, 4<<10)
+	resp.Body.Close()
+}
+
+var (
+	errUploadEnded  = errors.New("httpbackend: upload ended")
+	errWriterClosed = errors.New("httpbackend: write on closed writer")
+)
+
+// writer feeds the body of a PUT request running in the background
+type writer struct {
+	pw     *io.PipeWriter
+	done   chan error
+	err    error
+	closed bool
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errWriterClosed
+	}
+	n, err := w.pw.Write(p)
+	if err != nil {
+		// Report the request's failure instead of the closed pipe
+		w.closed = true
+		w.pw.Close()
+		w.err = <-w.done
+		if w.err == nil {
+			w.err = err
+		}
+		return n, w.err
+	}
+	return n, nil
+}
+
+// Close ends the request body and waits for the response
+func (w *writer) Close() error {
+	if w.closed {
+		return w.err
+	}
+	w.closed = true
+	w.pw.Close()
+	w.err = <-w.done
+	return w.err
+}
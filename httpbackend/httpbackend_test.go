@@ -0,0 +1,140 @@
+package httpbackend_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/httpbackend"
+	"schneider.vip/hybridbuffer/storage/storagetest"
+)
+
+// blobServer is a minimal blob service keeping one object per path
+type blobServer struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func (s *blobServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.blobs[r.URL.Path] = data
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet:
+		data, ok := s.blobs[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	case http.MethodDelete:
+		if _, ok := s.blobs[r.URL.Path]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+		delete(s.blobs, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newServer(t *testing.T, h http.Handler) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSuite(t *testing.T) {
+	srv := newServer(t, &blobServer{blobs: make(map[string][]byte)})
+	var n int
+	storagetest.RunSuite(t, func() storage.Backend {
+		n++
+		return httpbackend.New(srv.Client(), fmt.Sprintf("%s/blob-%d", srv.URL, n))
+	})
+}
+
+func TestHeader(t *testing.T) {
+	blobs := &blobServer{blobs: make(map[string][]byte)}
+	srv := newServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		blobs.ServeHTTP(w, r)
+	}))
+
+	b := httpbackend.New(srv.Client(), srv.URL+"/blob")
+	if _, err := b.Open(); !errors.Is(err, storage.ErrPermission) {
+		t.Fatalf("Open without header: got %v, want ErrPermission", err)
+	}
+
+	b = httpbackend.New(srv.Client(), srv.URL+"/blob", httpbackend.WithHeader("Authorization", "Bearer token"))
+	w, err := b.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(w, "data")
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close with header: %v", err)
+	}
+}
+
+func TestStatusErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusNotFound, storage.ErrNotFound},
+		{http.StatusForbidden, storage.ErrPermission},
+		{http.StatusConflict, storage.ErrAlreadyExists},
+		{http.StatusTooManyRequests, storage.ErrUnavailable},
+		{http.StatusBadGateway, storage.ErrUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			srv := newServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.Copy(io.Discard, r.Body)
+				w.WriteHeader(tt.status)
+			}))
+			b := httpbackend.New(srv.Client(), srv.URL+"/blob")
+
+			w, err := b.Create()
+			if err != nil {
+				t.Fatal(err)
+			}
+			io.WriteString(w, "data")
+			err = w.Close()
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Close: got %v, want %v", err, tt.want)
+			}
+			var statusErr *httpbackend.StatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.status {
+				t.Fatalf("Close: got %v, want a StatusError with %d", err, tt.status)
+			}
+		})
+	}
+}
+
+func TestUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL + "/blob"
+	srv.Close()
+
+	if _, err := httpbackend.New(nil, url).Open(); !errors.Is(err, storage.ErrUnavailable) {
+		t.Fatalf("Open: got %v, want ErrUnavailable", err)
+	}
+}
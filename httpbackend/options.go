@@ -0,0 +1,21 @@
+package httpbackend
+
+import "net/http"
+
+// Option configures the HTTP backend
+type Option func(*options)
+
+type options struct {
+	header http.Header
+}
+
+// WithHeader adds a header sent with every request, e.g. for
+// authentication. It may be given several times.
+func WithHeader(key, value string) Option {
+	return func(o *options) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		o.header.Add(key, value)
+	}
+}
@@ -2,7 +2,18 @@ package storage
 
 import "io"
 
-// Backend defines the minimal interface for external storage backends
+// Backend defines the minimal interface for external storage backends.
+//
+// A Backend represents a single storage location. Unless an implementation
+// documents otherwise, its methods are not safe for concurrent use and
+// callers must follow this contract:
+//
+//   - Data written through the writer returned by Create is only guaranteed
+//     to be visible to Open once the writer's Close has returned.
+//   - Open must not be called while a writer returned by Create is open.
+//   - Remove must not be called while a returned reader or writer is open.
+//
+// Use Synchronized to make a backend safe for concurrent calls.
 type Backend interface {
 	// Create creates a new storage location and returns a writer
 	Create() (io.WriteCloser, error)
@@ -12,4 +23,4 @@ type Backend interface {
 
 	// Remove removes the storage location
 	Remove() error
-}
\ No newline at end of file
+}
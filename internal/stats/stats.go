@@ -0,0 +1,41 @@
+// Package stats implements the operation counters behind the
+// storage.StatsReporter of the bundled backends
+package stats
+
+import (
+	"io"
+	"sync/atomic"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+// Counters holds the operation counts of a backend; the atomics allow
+// Snapshot to be called while streams are in use
+type Counters struct {
+	Creates      atomic.Int64
+	Opens        atomic.Int64
+	Removes      atomic.Int64
+	BytesWritten atomic.Int64
+	BytesRead    atomic.Int64
+	Errors       atomic.Int64
+}
+
+// Count records err as a failure unless it is nil or io.EOF and returns it
+func (c *Counters) Count(err error) error {
+	if err != nil && err != io.EOF {
+		c.Errors.Add(1)
+	}
+	return err
+}
+
+// Snapshot returns the current counts
+func (c *Counters) Snapshot() storage.BackendStats {
+	return storage.BackendStats{
+		Creates:      c.Creates.Load(),
+		Opens:        c.Opens.Load(),
+		Removes:      c.Removes.Load(),
+		BytesWritten: c.BytesWritten.Load(),
+		BytesRead:    c.BytesRead.Load(),
+		Errors:       c.Errors.Load(),
+	}
+}
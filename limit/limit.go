@@ -0,0 +1,94 @@
+// Package limit provides a storage backend wrapper that rejects writes
+// beyond a maximum size
+package limit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+// ErrLimitExceeded is returned by Write once the total size of a spill
+// would exceed the configured maximum
+var ErrLimitExceeded = errors.New("limit: size limit exceeded")
+
+type backend struct {
+	inner    storage.Backend
+	maxBytes int64
+}
+
+// Wrap returns a backend that accepts at most maxBytes per Create on b.
+// A Write that would cross the limit fails with ErrLimitExceeded and the
+// partially written location is removed. Open and Remove pass through.
+func Wrap(b storage.Backend, maxBytes int64) storage.Backend {
+	return &backend{inner: b, maxBytes: maxBytes}
+}
+
+// Create returns a writer that enforces the size limit on every Write
+func (b *backend) Create() (io.WriteCloser, error) {
+	w, err := b.inner.Create()
+	if err != nil {
+		return nil, err
+	}
+	return &writer{w: w, b: b}, nil
+}
+
+// Open opens the wrapped location
+func (b *backend) Open() (io.ReadCloser, error) {
+	return b.inner.Open()
+}
+
+// Remove removes the wrapped location
+func (b *backend) Remove() error {
+	return b.inner.Remove()
+}
+
+type writer struct {
+	w        io.WriteCloser
+	b        *backend
+	n        int64
+	exceeded error
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.exceeded != nil {
+		return 0, w.exceeded
+	}
+	if w.n+int64(len(p)) > w.b.maxBytes {
+		w.exceeded = fmt.Errorf("limit: %d bytes: %w", w.b.maxBytes, ErrLimitExceeded)
+		w.discard()
+		return 0, w.exceeded
+	}
+
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// Sync syncs the wrapped writer if it supports it
+func (w *writer) Sync() error {
+	if w.exceeded != nil {
+		return w.exceeded
+	}
+	return storage.Sync(w.w)
+}
+
+// Close closes the wrapped writer, or reports ErrLimitExceeded if the
+// limit was crossed
+func (w *writer) Close() error {
+	if w.exceeded != nil {
+		return w.exceeded
+	}
+	return w.w.Close()
+}
+
+// discard closes the wrapped writer and removes the partial data
+func (w *writer) discard() {
+	cerr := w.w.Close()
+	rerr := w.b.inner.Remove()
+	if err := errors.Join(cerr, rerr); err != nil {
+		w.exceeded = errors.Join(w.exceeded, fmt.Errorf("limit: discard partial data: %w", err))
+	}
+}
@@ -0,0 +1,61 @@
+package limit_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/filesystem"
+	"schneider.vip/hybridbuffer/storage/limit"
+	"schneider.vip/hybridbuffer/storage/memory"
+	"schneider.vip/hybridbuffer/storage/storagetest"
+)
+
+func TestSuite(t *testing.T) {
+	storagetest.RunSuite(t, func() storage.Backend {
+		return limit.Wrap(memory.New(), 2*storagetest.LargePayloadSize)
+	})
+}
+
+func TestExactLimit(t *testing.T) {
+	b := limit.Wrap(memory.New(), 10)
+	w, err := b.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, "0123456789"); err != nil {
+		t.Fatalf("writing up to the limit: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLimitExceeded(t *testing.T) {
+	inner := filesystem.New(t.TempDir())
+	b := limit.Wrap(inner, 10)
+	w, err := b.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, "01234567"); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := io.WriteString(w, "89ab")
+	if n != 0 || !errors.Is(err, limit.ErrLimitExceeded) {
+		t.Fatalf("Write: got %d, %v, want 0, ErrLimitExceeded", n, err)
+	}
+	if _, err := io.WriteString(w, "c"); !errors.Is(err, limit.ErrLimitExceeded) {
+		t.Fatalf("Write after exceeding: got %v, want ErrLimitExceeded", err)
+	}
+	if err := w.Close(); !errors.Is(err, limit.ErrLimitExceeded) {
+		t.Fatalf("Close: got %v, want ErrLimitExceeded", err)
+	}
+
+	// The partial write has been removed
+	if ok, err := storage.Exists(inner); ok || err != nil {
+		t.Fatalf("Exists: got %v, %v, want false", ok, err)
+	}
+}
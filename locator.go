@@ -0,0 +1,9 @@
+package storage
+
+// Locator mints backends for named locations within a shared namespace,
+// such as a directory or a key prefix in a bucket. Calling Location twice
+// with the same name addresses the same location.
+type Locator interface {
+	// Location returns the backend for the location called name
+	Location(name string) Backend
+}
@@ -0,0 +1,138 @@
+// Package logging provides a storage backend wrapper that logs every
+// operation with log/slog.
+//
+// Successful operations are logged at debug level, failed ones at error
+// level. Stream records are emitted when a reader or writer is closed and
+// include the number of bytes transferred. Payload data is never logged.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+type backend struct {
+	inner  storage.Backend
+	logger *slog.Logger
+}
+
+// Wrap returns a backend that logs every operation on b to logger.
+// Attributes set on logger, e.g. a buffer ID added with logger.With, are
+// included in every record. A nil logger is treated as slog.Default().
+func Wrap(b storage.Backend, logger *slog.Logger) storage.Backend {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &backend{inner: b, logger: logger}
+}
+
+// Create logs Create on the wrapped backend and the bytes written
+func (b *backend) Create() (io.WriteCloser, error) {
+	start := time.Now()
+	w, err := b.inner.Create()
+	b.log("storage create", time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	return &writer{w: w, b: b, start: time.Now()}, nil
+}
+
+// Open logs Open on the wrapped backend and the bytes read
+func (b *backend) Open() (io.ReadCloser, error) {
+	start := time.Now()
+	r, err := b.inner.Open()
+	b.log("storage open", time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	return &reader{r: r, b: b, start: time.Now()}, nil
+}
+
+// Remove logs Remove on the wrapped backend
+func (b *backend) Remove() error {
+	start := time.Now()
+	err := b.inner.Remove()
+	b.log("storage remove", time.Since(start), err)
+	return err
+}
+
+func (b *backend) log(msg string, d time.Duration, err error, attrs ...slog.Attr) {
+	level := slog.LevelDebug
+	attrs = append(attrs, slog.Duration("duration", d))
+	if err != nil {
+		level = slog.LevelError
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	b.logger.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+type writer struct {
+	w      io.WriteCloser
+	b      *backend
+	start  time.Time
+	n      int64
+	err    error
+	closed bool
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+	return n, err
+}
+
+// Sync syncs the wrapped writer if it supports it
+func (w *writer) Sync() error {
+	return storage.Sync(w.w)
+}
+
+func (w *writer) Close() error {
+	err := w.w.Close()
+	if !w.closed {
+		w.closed = true
+		logErr := w.err
+		if err != nil {
+			logErr = err
+		}
+		w.b.log("storage write", time.Since(w.start), logErr, slog.Int64("bytes", w.n))
+	}
+	return err
+}
+
+type reader struct {
+	r      io.ReadCloser
+	b      *backend
+	start  time.Time
+	n      int64
+	err    error
+	closed bool
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	if err != nil && err != io.EOF && r.err == nil {
+		r.err = err
+	}
+	return n, err
+}
+
+func (r *reader) Close() error {
+	err := r.r.Close()
+	if !r.closed {
+		r.closed = true
+		logErr := r.err
+		if err != nil {
+			logErr = err
+		}
+		r.b.log("storage read", time.Since(r.start), logErr, slog.Int64("bytes", r.n))
+	}
+	return err
+}
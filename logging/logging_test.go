@@ -0,0 +1,103 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/logging"
+	"schneider.vip/hybridbuffer/storage/memory"
+	"schneider.vip/hybridbuffer/storage/storagetest"
+)
+
+func TestSuite(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	storagetest.RunSuite(t, func() storage.Backend {
+		return logging.Wrap(memory.New(), logger)
+	})
+}
+
+// records decodes the JSON lines written by a slog.JSONHandler
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+func TestRecords(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	b := logging.Wrap(memory.New(), logger.With("buffer", "b1"))
+
+	w, err := b.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(w, "secret payload")
+	w.Close()
+	w.Close()
+
+	r, err := b.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.ReadAll(r)
+	r.Close()
+
+	b.Remove()
+	if _, err := b.Open(); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("Open after Remove: %v", err)
+	}
+
+	if strings.Contains(buf.String(), "secret payload") {
+		t.Fatal("payload data was logged")
+	}
+
+	want := []struct {
+		msg   string
+		level string
+		bytes float64
+	}{
+		{"storage create", "DEBUG", -1},
+		{"storage write", "DEBUG", 14},
+		{"storage open", "DEBUG", -1},
+		{"storage read", "DEBUG", 14},
+		{"storage remove", "DEBUG", -1},
+		{"storage open", "ERROR", -1},
+	}
+	recs := records(t, &buf)
+	if len(recs) != len(want) {
+		t.Fatalf("got %d records, want %d:\n%s", len(recs), len(want), buf.String())
+	}
+	for i, w := range want {
+		rec := recs[i]
+		if rec["msg"] != w.msg || rec["level"] != w.level {
+			t.Errorf("record %d = %v %v, want %v %v", i, rec["level"], rec["msg"], w.level, w.msg)
+		}
+		if rec["buffer"] != "b1" {
+			t.Errorf("record %d lacks the logger attributes: %v", i, rec)
+		}
+		if _, ok := rec["duration"]; !ok {
+			t.Errorf("record %d lacks a duration: %v", i, rec)
+		}
+		if w.bytes >= 0 && rec["bytes"] != w.bytes {
+			t.Errorf("record %d bytes = %v, want %v", i, rec["bytes"], w.bytes)
+		}
+		_, hasErr := rec["error"]
+		if hasErr != (w.level == "ERROR") {
+			t.Errorf("record %d error attribute = %v", i, rec["error"])
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Package manifest defines the versioned manifest format that backends
+// storing data across several locations use to describe it, so that
+// external tooling can inspect and reconstruct spills.
+//
+// Version 1 is encoded big-endian as
+//
+//	magic "HBMF" (4) | version (2) | chunk count (8) | chunk size (8) |
+//	total bytes (8) | checksum (4) | CRC-32C of the preceding bytes (4)
+package manifest
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// Version is the format version written by MarshalBinary
+const Version = 1
+
+const (
+	magic = "HBMF"
+	size  = 4 + 2 + 8 + 8 + 8 + 4 + 4
+)
+
+var (
+	// ErrInvalid is returned for data that is not a well-formed manifest
+	ErrInvalid = errors.New("manifest: invalid manifest")
+
+	// ErrUnsupportedVersion is returned for manifests written in a format
+	// version this package does not understand
+	ErrUnsupportedVersion = errors.New("manifest: unsupported version")
+)
+
+// castagnoli is the table used for the data and manifest checksums
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Manifest describes data split into chunks
+type Manifest struct {
+	// Version is the format version. MarshalBinary writes Version if it
+	// is zero.
+	Version uint16
+
+	// ChunkCount is the number of chunks
+	ChunkCount int64
+
+	// ChunkSize is the size of every chunk except the last one
+	ChunkSize int64
+
+	// TotalBytes is the size of the complete data
+	TotalBytes int64
+
+	// Checksum is the CRC-32C (Castagnoli) of the complete data
+	Checksum uint32
+}
+
+// Checksum returns the CRC-32C of data continuing from crc, for computing
+// Manifest.Checksum incrementally
+func Checksum(crc uint32, data []byte) uint32 {
+	return crc32.Update(crc, castagnoli, data)
+}
+
+// MarshalBinary encodes m in the format of its version
+func (m Manifest) MarshalBinary() ([]byte, error) {
+	version := m.Version
+	if version == 0 {
+		version = Version
+	}
+	if version != Version {
+		return nil, fmt.Errorf("%w %d", ErrUnsupportedVersion, version)
+	}
+	if m.ChunkCount < 0 || m.ChunkSize < 0 || m.TotalBytes < 0 {
+		return nil, fmt.Errorf("%w: negative field", ErrInvalid)
+	}
+
+	buf := make([]byte, 0, size)
+	buf = append(buf, magic...)
+	buf = binary.BigEndian.AppendUint16(buf, version)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(m.ChunkCount))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(m.ChunkSize))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(m.TotalBytes))
+	buf = binary.BigEndian.AppendUint32(buf, m.Checksum)
+	buf = binary.BigEndian.AppendUint32(buf, crc32.Checksum(buf, castagnoli))
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a manifest. It fails with ErrUnsupportedVersion
+// for unknown versions and with ErrInvalid for malformed or corrupted data.
+func (m *Manifest) UnmarshalBinary(data []byte) error {
+	if len(data) < 6 || string(data[:4]) != magic {
+		return fmt.Errorf("%w: bad magic", ErrInvalid)
+	}
+	if version := binary.BigEndian.Uint16(data[4:6]); version != Version {
+		return fmt.Errorf("%w %d", ErrUnsupportedVersion, version)
+	}
+	if len(data) != size {
+		return fmt.Errorf("%w: %d bytes", ErrInvalid, len(data))
+	}
+	body, sum := data[:size-4], binary.BigEndian.Uint32(data[size-4:])
+	if crc32.Checksum(body, castagnoli) != sum {
+		return fmt.Errorf("%w: checksum mismatch", ErrInvalid)
+	}
+
+	dec := Manifest{
+		Version:    Version,
+		ChunkCount: int64(binary.BigEndian.Uint64(data[6:14])),
+		ChunkSize:  int64(binary.BigEndian.Uint64(data[14:22])),
+		TotalBytes: int64(binary.BigEndian.Uint64(data[22:30])),
+		Checksum:   binary.BigEndian.Uint32(data[30:34]),
+	}
+	if dec.ChunkCount < 0 || dec.ChunkSize < 0 || dec.TotalBytes < 0 {
+		return fmt.Errorf("%w: negative field", ErrInvalid)
+	}
+	*m = dec
+	return nil
+}
@@ -0,0 +1,106 @@
+package manifest_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage/manifest"
+)
+
+func TestRoundTrip(t *testing.T) {
+	m := manifest.Manifest{
+		ChunkCount: 3,
+		ChunkSize:  1 << 20,
+		TotalBytes: 2<<20 + 17,
+		Checksum:   manifest.Checksum(0, []byte("data")),
+	}
+	data, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data[:4]) != "HBMF" {
+		t.Fatalf("encoding starts with %q, want the magic", data[:4])
+	}
+
+	var got manifest.Manifest
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	m.Version = manifest.Version
+	if got != m {
+		t.Fatalf("got %+v, want %+v", got, m)
+	}
+}
+
+func TestChecksumIncremental(t *testing.T) {
+	whole := manifest.Checksum(0, []byte("hello world"))
+	parts := manifest.Checksum(manifest.Checksum(0, []byte("hello ")), []byte("world"))
+	if whole != parts {
+		t.Fatalf("incremental checksum %08x, want %08x", parts, whole)
+	}
+}
+
+func TestMarshalUnsupportedVersion(t *testing.T) {
+	_, err := manifest.Manifest{Version: 2}.MarshalBinary()
+	if !errors.Is(err, manifest.ErrUnsupportedVersion) {
+		t.Fatalf("got %v, want ErrUnsupportedVersion", err)
+	}
+}
+
+func TestMarshalNegativeField(t *testing.T) {
+	_, err := manifest.Manifest{TotalBytes: -1}.MarshalBinary()
+	if !errors.Is(err, manifest.ErrInvalid) {
+		t.Fatalf("got %v, want ErrInvalid", err)
+	}
+}
+
+// valid returns the encoding of a well-formed manifest
+func valid(t *testing.T) []byte {
+	t.Helper()
+	data, err := manifest.Manifest{ChunkCount: 1, ChunkSize: 10, TotalBytes: 10}.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// reseal recomputes the trailing CRC after data was modified
+func reseal(data []byte) []byte {
+	n := len(data) - 4
+	binary.BigEndian.PutUint32(data[n:], crc32.Checksum(data[:n], crc32.MakeTable(crc32.Castagnoli)))
+	return data
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func([]byte) []byte
+		want   error
+	}{
+		{"Empty", func([]byte) []byte { return nil }, manifest.ErrInvalid},
+		{"BadMagic", func(d []byte) []byte { d[0] = 'X'; return d }, manifest.ErrInvalid},
+		{"Truncated", func(d []byte) []byte { return d[:len(d)-1] }, manifest.ErrInvalid},
+		{"TrailingData", func(d []byte) []byte { return append(d, 0) }, manifest.ErrInvalid},
+		{"BadCRC", func(d []byte) []byte { d[len(d)-1] ^= 1; return d }, manifest.ErrInvalid},
+		{"ModifiedField", func(d []byte) []byte { d[20] ^= 1; return d }, manifest.ErrInvalid},
+		{"NegativeField", func(d []byte) []byte { d[22] = 0x80; return reseal(d) }, manifest.ErrInvalid},
+		{"UnsupportedVersion", func(d []byte) []byte {
+			binary.BigEndian.PutUint16(d[4:6], 2)
+			return reseal(d)
+		}, manifest.ErrUnsupportedVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m manifest.Manifest
+			err := m.UnmarshalBinary(tt.modify(valid(t)))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+			if m != (manifest.Manifest{}) {
+				t.Fatalf("failed decode modified the manifest: %+v", m)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package memory
+
+import (
+	"sync"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+type locator struct {
+	mu        sync.Mutex
+	locations map[string]storage.Backend
+}
+
+// NewLocator returns a Locator that keeps every named location in memory.
+// Location returns the same backend for the same name.
+func NewLocator() storage.Locator {
+	return &locator{locations: make(map[string]storage.Backend)}
+}
+
+// Location returns the in-memory backend called name, creating it if needed
+func (l *locator) Location(name string) storage.Backend {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.locations[name]
+	if !ok {
+		b = New()
+		l.locations[name] = b
+	}
+	return b
+}
@@ -0,0 +1,172 @@
+// Package memory provides an in-memory storage backend, mainly for tests.
+//
+// Backend methods and the returned streams are safe for concurrent use.
+// Open returns a snapshot of the committed data; a later Create does not
+// affect readers that are already open.
+package memory
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/internal/stats"
+)
+
+// ErrNotFound is returned by Open before a Create/Close cycle has completed.
+// It is the shared storage.ErrNotFound sentinel.
+var ErrNotFound = storage.ErrNotFound
+
+var errStaleWriter = errors.New("memory: writer superseded by a newer Create")
+
+type backend struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	gen       uint64
+	committed bool
+
+	stats stats.Counters
+}
+
+// New creates an empty in-memory backend
+func New() storage.Backend {
+	return &backend{}
+}
+
+// Create resets the buffer and returns a writer. The written data becomes
+// visible to Open once the writer is closed.
+func (b *backend) Create() (io.WriteCloser, error) {
+	b.stats.Creates.Add(1)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf.Reset()
+	b.committed = false
+	b.gen++
+	return &writer{b: b, gen: b.gen}, nil
+}
+
+// Open returns a seekable reader over the committed bytes
+func (b *backend) Open() (io.ReadCloser, error) {
+	b.stats.Opens.Add(1)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.committed {
+		return nil, b.stats.Count(fmt.Errorf("memory: open: %w", ErrNotFound))
+	}
+	data := bytes.Clone(b.buf.Bytes())
+	return &reader{r: bytes.NewReader(data), stats: &b.stats}, nil
+}
+
+// Remove drops the stored data
+func (b *backend) Remove() error {
+	b.stats.Removes.Add(1)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf = bytes.Buffer{}
+	b.committed = false
+	b.gen++
+	return nil
+}
+
+// Reset drops the stored data but keeps the allocated buffer for reuse.
+// Calling Reset while a writer is open is undefined.
+func (b *backend) Reset() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf.Reset()
+	b.committed = false
+	b.gen++
+	return nil
+}
+
+// Size returns the number of committed bytes
+func (b *backend) Size() (int64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.committed {
+		return 0, fmt.Errorf("memory: size: %w", ErrNotFound)
+	}
+	return int64(b.buf.Len()), nil
+}
+
+// Exists reports whether committed data is present
+func (b *backend) Exists() (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.committed, nil
+}
+
+// reader counts the bytes read from a bytes.Reader and adds a no-op Close
+type reader struct {
+	r     *bytes.Reader
+	stats *stats.Counters
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.stats.BytesRead.Add(int64(n))
+	return n, r.stats.Count(err)
+}
+
+func (r *reader) ReadAt(p []byte, off int64) (int, error) {
+	n, err := r.r.ReadAt(p, off)
+	r.stats.BytesRead.Add(int64(n))
+	return n, r.stats.Count(err)
+}
+
+func (r *reader) WriteTo(w io.Writer) (int64, error) {
+	n, err := r.r.WriteTo(w)
+	r.stats.BytesRead.Add(n)
+	return n, r.stats.Count(err)
+}
+
+func (r *reader) Seek(offset int64, whence int) (int64, error) {
+	n, err := r.r.Seek(offset, whence)
+	return n, r.stats.Count(err)
+}
+
+func (r *reader) Close() error { return nil }
+
+type writer struct {
+	b      *backend
+	gen    uint64
+	closed bool
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	w.b.mu.Lock()
+	defer w.b.mu.Unlock()
+
+	if w.closed {
+		return 0, w.b.stats.Count(errors.New("memory: write on closed writer"))
+	}
+	if w.gen != w.b.gen {
+		return 0, w.b.stats.Count(errStaleWriter)
+	}
+	n, _ := w.b.buf.Write(p)
+	w.b.stats.BytesWritten.Add(int64(n))
+	return n, nil
+}
+
+func (w *writer) Close() error {
+	w.b.mu.Lock()
+	defer w.b.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.gen != w.b.gen {
+		return w.b.stats.Count(errStaleWriter)
+	}
+	w.b.committed = true
+	return nil
+}
@@ -0,0 +1,65 @@
+package memory_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/memory"
+	"schneider.vip/hybridbuffer/storage/storagetest"
+)
+
+func TestSuite(t *testing.T) {
+	storagetest.RunSuite(t, memory.New)
+}
+
+// writes records the size of every Write
+type writes struct {
+	sizes []int
+}
+
+func (w *writes) Write(p []byte) (int, error) {
+	w.sizes = append(w.sizes, len(p))
+	return len(p), nil
+}
+
+func (w *writes) Close() error { return nil }
+
+// sink is a backend whose writer is w
+type sink struct {
+	storage.Backend
+	w *writes
+}
+
+func (s sink) Create() (io.WriteCloser, error) { return s.w, nil }
+
+func TestCopyWriteTo(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 100<<10)
+	src := memory.New()
+	w, _ := src.Create()
+	w.Write(data)
+	w.Close()
+
+	// The reader's WriteTo hands over the data in a single Write
+	fast := &writes{}
+	if _, err := storage.Copy(sink{memory.New(), fast}, src); err != nil {
+		t.Fatal(err)
+	}
+	if len(fast.sizes) != 1 || fast.sizes[0] != len(data) {
+		t.Fatalf("fast path wrote %v, want a single write of %d bytes", fast.sizes, len(data))
+	}
+
+	buffered := &writes{}
+	if _, err := storage.Copy(sink{memory.New(), buffered}, storagetest.Plain(src)); err != nil {
+		t.Fatal(err)
+	}
+	if len(buffered.sizes) < 2 {
+		t.Fatalf("buffered copy wrote %v, want several chunks", buffered.sizes)
+	}
+
+	stats, _ := storage.GetStats(src)
+	if stats.BytesRead != 2*int64(len(data)) || stats.Errors != 0 {
+		t.Fatalf("Stats = %+v, want %d bytes read and no errors", stats, 2*len(data))
+	}
+}
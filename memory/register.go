@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"net/url"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+func init() {
+	storage.Register("memory", func(*url.URL) (storage.Backend, error) {
+		return New(), nil
+	})
+}
@@ -0,0 +1,8 @@
+package memory
+
+import "schneider.vip/hybridbuffer/storage"
+
+// Stats returns the operation counters of the backend
+func (b *backend) Stats() storage.BackendStats {
+	return b.stats.Snapshot()
+}
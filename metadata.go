@@ -0,0 +1,21 @@
+package storage
+
+// MetadataSetter is implemented by backends that can store metadata with
+// the data, typically object stores
+type MetadataSetter interface {
+	// SetMetadata sets the metadata stored by subsequent Create calls.
+	// It replaces metadata set by earlier calls.
+	SetMetadata(md map[string]string)
+}
+
+// SetMetadata sets the metadata stored with the data written to b and
+// reports whether b supports metadata. Backends that do not implement
+// MetadataSetter ignore it.
+func SetMetadata(b Backend, md map[string]string) bool {
+	ms, ok := b.(MetadataSetter)
+	if !ok {
+		return false
+	}
+	ms.SetMetadata(md)
+	return true
+}
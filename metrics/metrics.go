@@ -0,0 +1,101 @@
+// Package metrics provides a storage backend wrapper that reports
+// operation timings and transferred bytes to a Recorder
+package metrics
+
+import (
+	"io"
+	"time"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+type backend struct {
+	inner storage.Backend
+	rec   Recorder
+}
+
+// Wrap returns a backend that reports every operation on b to r.
+// A nil r is treated as NopRecorder.
+func Wrap(b storage.Backend, r Recorder) storage.Backend {
+	if r == nil {
+		r = NopRecorder{}
+	}
+	return &backend{inner: b, rec: r}
+}
+
+// Create times Create on the wrapped backend and counts written bytes
+func (b *backend) Create() (io.WriteCloser, error) {
+	start := time.Now()
+	w, err := b.inner.Create()
+	b.rec.ObserveCreate(time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	return &writer{w: w, rec: b.rec}, nil
+}
+
+// Open times Open on the wrapped backend and counts read bytes
+func (b *backend) Open() (io.ReadCloser, error) {
+	start := time.Now()
+	r, err := b.inner.Open()
+	b.rec.ObserveOpen(time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	return &reader{r: r, rec: b.rec}, nil
+}
+
+// Remove times Remove on the wrapped backend
+func (b *backend) Remove() error {
+	start := time.Now()
+	err := b.inner.Remove()
+	b.rec.ObserveRemove(time.Since(start), err)
+	return err
+}
+
+type writer struct {
+	w      io.WriteCloser
+	rec    Recorder
+	n      int64
+	closed bool
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// Sync syncs the wrapped writer if it supports it
+func (w *writer) Sync() error {
+	return storage.Sync(w.w)
+}
+
+func (w *writer) Close() error {
+	if !w.closed {
+		w.closed = true
+		w.rec.ObserveBytes(OpWrite, w.n)
+	}
+	return w.w.Close()
+}
+
+type reader struct {
+	r      io.ReadCloser
+	rec    Recorder
+	n      int64
+	closed bool
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func (r *reader) Close() error {
+	if !r.closed {
+		r.closed = true
+		r.rec.ObserveBytes(OpRead, r.n)
+	}
+	return r.r.Close()
+}
@@ -0,0 +1,113 @@
+package metrics_test
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/memory"
+	"schneider.vip/hybridbuffer/storage/metrics"
+	"schneider.vip/hybridbuffer/storage/storagetest"
+)
+
+func TestSuite(t *testing.T) {
+	storagetest.RunSuite(t, func() storage.Backend {
+		return metrics.Wrap(memory.New(), metrics.NopRecorder{})
+	})
+}
+
+// recorder keeps every observation for inspection
+type recorder struct {
+	mu    sync.Mutex
+	ops   []string
+	errs  []error
+	bytes map[string]int64
+}
+
+func (r *recorder) observe(op string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ops = append(r.ops, op)
+	r.errs = append(r.errs, err)
+}
+
+func (r *recorder) ObserveCreate(_ time.Duration, err error) { r.observe("create", err) }
+func (r *recorder) ObserveOpen(_ time.Duration, err error)   { r.observe("open", err) }
+func (r *recorder) ObserveRemove(_ time.Duration, err error) { r.observe("remove", err) }
+
+func (r *recorder) ObserveBytes(op string, n int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.bytes == nil {
+		r.bytes = make(map[string]int64)
+	}
+	r.bytes[op] += n
+}
+
+func TestObservations(t *testing.T) {
+	rec := &recorder{}
+	b := metrics.Wrap(memory.New(), rec)
+
+	w, err := b.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(w, "hello world")
+	w.Close()
+	w.Close()
+
+	r, err := b.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.CopyN(io.Discard, r, 5)
+	r.Close()
+
+	if err := b.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Open(); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("Open after Remove: %v", err)
+	}
+
+	want := []string{"create", "open", "remove", "open"}
+	if len(rec.ops) != len(want) {
+		t.Fatalf("got ops %v, want %v", rec.ops, want)
+	}
+	for i, op := range want {
+		if rec.ops[i] != op {
+			t.Fatalf("got ops %v, want %v", rec.ops, want)
+		}
+	}
+	for i, err := range rec.errs[:3] {
+		if err != nil {
+			t.Errorf("%s observed error %v", rec.ops[i], err)
+		}
+	}
+	if !errors.Is(rec.errs[3], storage.ErrNotFound) {
+		t.Errorf("failed open observed %v, want ErrNotFound", rec.errs[3])
+	}
+
+	// A second Close must not report the stream again
+	if got := rec.bytes[metrics.OpWrite]; got != 11 {
+		t.Errorf("write bytes = %d, want 11", got)
+	}
+	if got := rec.bytes[metrics.OpRead]; got != 5 {
+		t.Errorf("read bytes = %d, want 5", got)
+	}
+}
+
+func TestNilRecorder(t *testing.T) {
+	b := metrics.Wrap(memory.New(), nil)
+	w, err := b.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(w, "data")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
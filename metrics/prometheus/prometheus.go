@@ -0,0 +1,63 @@
+// Package prometheus provides a metrics.Recorder backed by Prometheus
+// collectors
+package prometheus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"schneider.vip/hybridbuffer/storage/metrics"
+)
+
+// Recorder exports backend measurements as Prometheus metrics:
+//
+//	<namespace>_storage_operation_duration_seconds{operation, result}
+//	<namespace>_storage_bytes_total{operation}
+type Recorder struct {
+	duration *prometheus.HistogramVec
+	bytes    *prometheus.CounterVec
+}
+
+var _ metrics.Recorder = (*Recorder)(nil)
+
+// NewRecorder creates a Recorder and registers its collectors with reg
+func NewRecorder(reg prometheus.Registerer, namespace string) (*Recorder, error) {
+	r := &Recorder{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of storage backend operations.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation", "result"}),
+		bytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "bytes_total",
+			Help:      "Bytes transferred through storage backend streams.",
+		}, []string{"operation"}),
+	}
+	for _, c := range []prometheus.Collector{r.duration, r.bytes} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *Recorder) ObserveCreate(d time.Duration, err error) { r.observe("create", d, err) }
+func (r *Recorder) ObserveOpen(d time.Duration, err error)   { r.observe("open", d, err) }
+func (r *Recorder) ObserveRemove(d time.Duration, err error) { r.observe("remove", d, err) }
+
+func (r *Recorder) ObserveBytes(op string, n int64) {
+	r.bytes.WithLabelValues(op).Add(float64(n))
+}
+
+func (r *Recorder) observe(op string, d time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	r.duration.WithLabelValues(op, result).Observe(d.Seconds())
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import "time"
+
+// Stream operations reported to ObserveBytes
+const (
+	OpRead  = "read"
+	OpWrite = "write"
+)
+
+// Recorder receives measurements from an instrumented backend
+type Recorder interface {
+	// ObserveCreate records the duration and result of establishing a writer
+	ObserveCreate(d time.Duration, err error)
+
+	// ObserveOpen records the duration and result of establishing a reader
+	ObserveOpen(d time.Duration, err error)
+
+	// ObserveRemove records the duration and result of a Remove call
+	ObserveRemove(d time.Duration, err error)
+
+	// ObserveBytes records the number of bytes transferred by a stream once
+	// it is closed. op is OpRead or OpWrite.
+	ObserveBytes(op string, n int64)
+}
+
+// NopRecorder is a Recorder that discards all measurements
+type NopRecorder struct{}
+
+func (NopRecorder) ObserveCreate(time.Duration, error) {}
+func (NopRecorder) ObserveOpen(time.Duration, error)   {}
+func (NopRecorder) ObserveRemove(time.Duration, error) {}
+func (NopRecorder) ObserveBytes(string, int64)         {}
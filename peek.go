@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Peek opens the storage location of b and reads up to n bytes from its
+// start. It returns those bytes together with a reader that yields the
+// whole data from the beginning, including the peeked bytes. Fewer than
+// n bytes are returned if the data is shorter.
+//
+// If the reader returned by Open is an io.ReadSeekCloser, it is seeked
+// back to the start and returned as is; otherwise the peeked bytes are
+// replayed before the rest of the stream. Closing the returned reader
+// closes the underlying one.
+func Peek(b Backend, n int) ([]byte, io.ReadCloser, error) {
+	if n < 0 {
+		return nil, nil, errors.New("storage: peek: negative count")
+	}
+	r, err := b.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	buf := make([]byte, n)
+	m, err := io.ReadFull(r, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		r.Close()
+		return nil, nil, fmt.Errorf("storage: peek: %w", err)
+	}
+	buf = buf[:m]
+
+	if s, ok := r.(io.ReadSeekCloser); ok {
+		if _, err := s.Seek(0, io.SeekStart); err != nil {
+			r.Close()
+			return nil, nil, fmt.Errorf("storage: peek: %w", err)
+		}
+		return buf, s, nil
+	}
+	return buf, &peekReader{Reader: io.MultiReader(bytes.NewReader(buf), r), Closer: r}, nil
+}
+
+type peekReader struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,48 @@
+package storage_test
+
+import (
+	"io"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/memory"
+)
+
+func TestPeek(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		want string
+	}{
+		{"Prefix", 4, "0123"},
+		{"Zero", 0, ""},
+		{"Longer", 20, "0123456789"},
+	}
+	for _, seekable := range []bool{true, false} {
+		var b storage.Backend = memory.New()
+		write(t, b, "0123456789")
+		if !seekable {
+			b = streamOnly{b}
+		}
+		for _, tt := range tests {
+			head, r, err := storage.Peek(b, tt.n)
+			if err != nil {
+				t.Fatalf("%s (seekable %v): %v", tt.name, seekable, err)
+			}
+			data, err := io.ReadAll(r)
+			r.Close()
+			if string(head) != tt.want {
+				t.Errorf("%s (seekable %v): peeked %q, want %q", tt.name, seekable, head, tt.want)
+			}
+			if err != nil || string(data) != "0123456789" {
+				t.Errorf("%s (seekable %v): reader yields %q, %v, want the whole data", tt.name, seekable, data, err)
+			}
+		}
+	}
+}
+
+func TestPeekNegative(t *testing.T) {
+	if _, _, err := storage.Peek(memory.New(), -1); err == nil {
+		t.Fatal("negative count accepted")
+	}
+}
@@ -0,0 +1,28 @@
+package pipe
+
+import "time"
+
+// Option configures the pipe backend
+type Option func(*options)
+
+type options struct {
+	dialTimeout time.Duration
+	unlink      bool
+}
+
+// WithDialTimeout bounds how long Create waits for the connection.
+// Zero, the default, waits as long as the operating system allows.
+func WithDialTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.dialTimeout = d
+	}
+}
+
+// WithUnlink makes Remove unlink the socket file after closing the
+// connection. Use it only for sockets dedicated to a single spill, since
+// the listening process cannot accept new connections afterwards.
+func WithUnlink(enabled bool) Option {
+	return func(o *options) {
+		o.unlink = enabled
+	}
+}
@@ -0,0 +1,164 @@
+// Package pipe provides a storage backend that streams data to another
+// process over a Unix domain socket, e.g. a sidecar that keeps overflow
+// data for this process.
+//
+// Create connects to the socket and the writer sends the data; closing
+// the writer shuts down the sending side of the connection. Open returns
+// a reader for the data the peer sends back on the same connection. A
+// socket is neither seekable nor re-readable, so a spill is one-shot:
+// Open succeeds once per Create and fails with ErrConsumed afterwards.
+//
+// Backend methods are safe for concurrent use.
+package pipe
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+// ErrConsumed is returned by Open if the data of the last Create has
+// already been read
+var ErrConsumed = errors.New("pipe: data already consumed")
+
+type backend struct {
+	path string
+	opts options
+
+	mu     sync.Mutex
+	conn   *net.UnixConn
+	opened bool
+}
+
+// New creates a backend streaming its data over the Unix socket at path
+func New(path string, opts ...Option) storage.Backend {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &backend{path: path, opts: o}
+}
+
+// Create connects to the socket and returns a writer for the connection.
+// A connection left over from a previous Create is closed first.
+func (b *backend) Create() (io.WriteCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closeLocked()
+	c, err := net.DialTimeout("unix", b.path, b.opts.dialTimeout)
+	if err != nil {
+		return nil, mapError("dial", err)
+	}
+	b.conn = c.(*net.UnixConn)
+	b.opened = false
+	return &writer{conn: b.conn}, nil
+}
+
+// Open returns a reader for the data the peer sends back. It can be
+// called only once per Create.
+func (b *backend) Open() (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.opened {
+		return nil, ErrConsumed
+	}
+	if b.conn == nil {
+		return nil, fmt.Errorf("pipe: open %s: %w", b.path, storage.ErrNotFound)
+	}
+	b.opened = true
+	return &reader{conn: b.conn}, nil
+}
+
+// Remove closes the connection and, with WithUnlink, unlinks the socket
+func (b *backend) Remove() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closeLocked()
+	b.opened = false
+	if b.opts.unlink {
+		if err := os.Remove(b.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return mapError("unlink", err)
+		}
+	}
+	return nil
+}
+
+func (b *backend) closeLocked() {
+	if b.conn != nil {
+		b.conn.Close()
+		b.conn = nil
+	}
+}
+
+// writer sends data and half-closes the connection on Close, so the peer
+// sees the end of the data while the connection stays open for reading
+type writer struct {
+	conn   *net.UnixConn
+	closed bool
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	n, err := w.conn.Write(p)
+	if err != nil {
+		return n, mapError("write", err)
+	}
+	return n, nil
+}
+
+func (w *writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if err := w.conn.CloseWrite(); err != nil {
+		return mapError("close write", err)
+	}
+	return nil
+}
+
+// reader receives the data sent back by the peer
+type reader struct {
+	conn *net.UnixConn
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.conn.Read(p)
+	if err != nil && err != io.EOF {
+		return n, mapError("read", err)
+	}
+	return n, err
+}
+
+// Close ends the exchange and closes the connection
+func (r *reader) Close() error {
+	if err := r.conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		return mapError("close", err)
+	}
+	return nil
+}
+
+// mapError wraps err and maps socket failures onto the storage sentinel errors
+func mapError(op string, err error) error {
+	var sentinel error
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		sentinel = storage.ErrNotFound
+	case errors.Is(err, fs.ErrPermission):
+		sentinel = storage.ErrPermission
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, os.ErrDeadlineExceeded):
+		sentinel = storage.ErrUnavailable
+	default:
+		return fmt.Errorf("pipe: %s: %w", op, err)
+	}
+	return fmt.Errorf("pipe: %s: %w: %w", op, sentinel, err)
+}
@@ -0,0 +1,113 @@
+package pipe_test
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/pipe"
+	"schneider.vip/hybridbuffer/storage/storagetest"
+)
+
+// sidecar listens on a Unix socket and sends every connection's data back
+// once the sending side is shut down
+func sidecar(t *testing.T) string {
+	t.Helper()
+	// Socket paths are limited to about 100 bytes, which t.TempDir can exceed
+	dir, err := os.MkdirTemp("", "pipe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				data, err := io.ReadAll(c)
+				if err != nil {
+					return
+				}
+				c.Write(data)
+			}()
+		}
+	}()
+	return path
+}
+
+func TestSuite(t *testing.T) {
+	path := sidecar(t)
+	storagetest.RunSuite(t, func() storage.Backend {
+		return pipe.New(path)
+	})
+}
+
+func TestOpenOncePerCreate(t *testing.T) {
+	b := pipe.New(sidecar(t))
+
+	for range 2 {
+		w, err := b.Create()
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, "data")
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := b.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := io.ReadAll(r); string(data) != "data" {
+			t.Fatalf("got %q, want %q", data, "data")
+		}
+		r.Close()
+
+		if _, err := b.Open(); !errors.Is(err, pipe.ErrConsumed) {
+			t.Fatalf("second Open: got %v, want ErrConsumed", err)
+		}
+	}
+}
+
+func TestNoListener(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing")
+	if _, err := pipe.New(path).Create(); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("Create: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestUnlink(t *testing.T) {
+	path := sidecar(t)
+	b := pipe.New(path, pipe.WithUnlink(true))
+	w, err := b.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	if err := b.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("socket still exists: %v", err)
+	}
+	if err := b.Remove(); err != nil {
+		t.Fatalf("second Remove: %v", err)
+	}
+}
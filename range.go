@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// RangeOpener is implemented by backends that can read a byte range
+// natively, e.g. with a ranged HTTP request
+type RangeOpener interface {
+	// OpenRange opens length bytes of the location starting at off.
+	// A length of -1 reads to the end. It returns an error wrapping
+	// ErrNotFound if the location does not exist.
+	OpenRange(off, length int64) (io.ReadCloser, error)
+}
+
+// OpenRange opens length bytes of the storage location of b starting at
+// off. A length of -1 reads to the end of the data, and a range beyond the
+// end yields fewer bytes or none at all.
+//
+// Backends implementing RangeOpener serve the range natively. Otherwise
+// the reader returned by Open is seeked to off if it is an
+// io.ReadSeekCloser, or the first off bytes are read and discarded.
+func OpenRange(b Backend, off, length int64) (io.ReadCloser, error) {
+	if off < 0 {
+		return nil, errors.New("storage: open range: negative offset")
+	}
+	if length < -1 {
+		return nil, errors.New("storage: open range: invalid length")
+	}
+	if ro, ok := b.(RangeOpener); ok {
+		return ro.OpenRange(off, length)
+	}
+
+	r, err := b.Open()
+	if err != nil {
+		return nil, err
+	}
+	if err := skip(r, off); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("storage: open range: %w", err)
+	}
+	if length < 0 {
+		return r, nil
+	}
+	return &rangeReader{Reader: io.LimitReader(r, length), Closer: r}, nil
+}
+
+// skip advances r by off bytes, seeking if possible. Reaching the end of
+// the data early is not an error.
+func skip(r io.Reader, off int64) error {
+	if off == 0 {
+		return nil
+	}
+	if s, ok := r.(io.Seeker); ok {
+		_, err := s.Seek(off, io.SeekStart)
+		return err
+	}
+	_, err := io.CopyN(io.Discard, r, off)
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+type rangeReader struct {
+	io.Reader
+	io.Closer
+}
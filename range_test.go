@@ -0,0 +1,89 @@
+package storage_test
+
+import (
+	"io"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/memory"
+)
+
+// streamOnly hides the io.Seeker of the readers returned by a backend
+type streamOnly struct {
+	storage.Backend
+}
+
+func (s streamOnly) Open() (io.ReadCloser, error) {
+	r, err := s.Backend.Open()
+	if err != nil {
+		return nil, err
+	}
+	return struct{ io.ReadCloser }{r}, nil
+}
+
+// ranged records the range passed to OpenRange
+type ranged struct {
+	storage.Backend
+	off, length int64
+}
+
+func (r *ranged) OpenRange(off, length int64) (io.ReadCloser, error) {
+	r.off, r.length = off, length
+	return r.Open()
+}
+
+func TestOpenRange(t *testing.T) {
+	tests := []struct {
+		name        string
+		off, length int64
+		want        string
+	}{
+		{"Middle", 2, 3, "234"},
+		{"ToEnd", 7, -1, "789"},
+		{"PastEnd", 8, 10, "89"},
+		{"BeyondEnd", 20, 5, ""},
+		{"Empty", 3, 0, ""},
+	}
+	for _, seekable := range []bool{true, false} {
+		var b storage.Backend = memory.New()
+		write(t, b, "0123456789")
+		if !seekable {
+			b = streamOnly{b}
+		}
+		for _, tt := range tests {
+			r, err := storage.OpenRange(b, tt.off, tt.length)
+			if err != nil {
+				t.Fatalf("%s (seekable %v): %v", tt.name, seekable, err)
+			}
+			data, err := io.ReadAll(r)
+			r.Close()
+			if err != nil || string(data) != tt.want {
+				t.Errorf("%s (seekable %v): got %q, %v, want %q", tt.name, seekable, data, err, tt.want)
+			}
+		}
+	}
+}
+
+func TestOpenRangeNative(t *testing.T) {
+	b := &ranged{Backend: memory.New()}
+	write(t, b, "data")
+	r, err := storage.OpenRange(b, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	if b.off != 1 || b.length != 2 {
+		t.Fatalf("RangeOpener got (%d, %d), want (1, 2)", b.off, b.length)
+	}
+}
+
+func TestOpenRangeInvalid(t *testing.T) {
+	b := memory.New()
+	write(t, b, "data")
+	if _, err := storage.OpenRange(b, -1, 1); err == nil {
+		t.Error("negative offset accepted")
+	}
+	if _, err := storage.OpenRange(b, 0, -2); err == nil {
+		t.Error("length -2 accepted")
+	}
+}
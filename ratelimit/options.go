@@ -0,0 +1,29 @@
+package ratelimit
+
+import "golang.org/x/time/rate"
+
+// Option configures the rate-limiting wrapper
+type Option func(*options)
+
+type options struct {
+	limiter *rate.Limiter
+	burst   int
+}
+
+// WithLimiter makes the wrapper draw its tokens from l instead of a
+// limiter of its own. Sharing one limiter between several wrapped backends
+// caps their aggregate throughput. The bytesPerSec argument of Wrap and
+// WithBurst are ignored if a limiter is given.
+func WithLimiter(l *rate.Limiter) Option {
+	return func(o *options) {
+		o.limiter = l
+	}
+}
+
+// WithBurst sets the number of bytes that may be transferred at once
+// without waiting. It defaults to one second worth of bytes.
+func WithBurst(n int) Option {
+	return func(o *options) {
+		o.burst = n
+	}
+}
@@ -0,0 +1,197 @@
+// Package ratelimit provides a storage backend wrapper that caps the
+// throughput of the returned streams with a token bucket
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"math"
+
+	"golang.org/x/time/rate"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+type backend struct {
+	inner   storage.Backend
+	limiter *rate.Limiter
+}
+
+// Wrap returns a backend whose readers and writers transfer at most
+// bytesPerSec bytes per second. A bytesPerSec <= 0 disables limiting.
+// Establishing streams and Remove are not throttled.
+//
+// Waiting for tokens honours the context passed to CreateContext or
+// OpenContext and fails with ctx.Err() once it is done.
+func Wrap(b storage.Backend, bytesPerSec int64, opts ...Option) storage.Backend {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	l := o.limiter
+	if l == nil {
+		l = newLimiter(bytesPerSec, o.burst)
+	}
+	return &backend{inner: b, limiter: l}
+}
+
+func newLimiter(bytesPerSec int64, burst int) *rate.Limiter {
+	if bytesPerSec <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	if burst <= 0 {
+		burst = int(min(bytesPerSec, math.MaxInt32))
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSec), burst)
+}
+
+// Create returns a throttled writer
+func (b *backend) Create() (io.WriteCloser, error) {
+	w, err := b.inner.Create()
+	if err != nil {
+		return nil, err
+	}
+	return newWriter(context.Background(), w, b.limiter), nil
+}
+
+// CreateContext returns a throttled writer that stops waiting once ctx is
+// done. The wrapped writer is bound to ctx with storage.CreateContext, so
+// the location is removed if ctx is cancelled before Close.
+func (b *backend) CreateContext(ctx context.Context) (io.WriteCloser, error) {
+	w, err := storage.CreateContext(ctx, b.inner)
+	if err != nil {
+		return nil, err
+	}
+	return newWriter(ctx, w, b.limiter), nil
+}
+
+// Open returns a throttled reader
+func (b *backend) Open() (io.ReadCloser, error) {
+	r, err := b.inner.Open()
+	if err != nil {
+		return nil, err
+	}
+	return newReader(context.Background(), r, b.limiter), nil
+}
+
+// OpenContext returns a throttled reader that stops waiting once ctx is
+// done. The wrapped reader is bound to ctx with storage.OpenContext.
+func (b *backend) OpenContext(ctx context.Context) (io.ReadCloser, error) {
+	r, err := storage.OpenContext(ctx, b.inner)
+	if err != nil {
+		return nil, err
+	}
+	return newReader(ctx, r, b.limiter), nil
+}
+
+// Remove removes the wrapped location
+func (b *backend) Remove() error {
+	return b.inner.Remove()
+}
+
+// RemoveContext removes the wrapped location bound to ctx
+func (b *backend) RemoveContext(ctx context.Context) error {
+	return storage.RemoveContext(ctx, b.inner)
+}
+
+// chunk returns how many bytes of n may be transferred in one step.
+// WaitN fails for requests larger than the burst, so transfers are split.
+func chunk(l *rate.Limiter, n int) int {
+	if l.Limit() == rate.Inf {
+		return n
+	}
+	return min(n, max(l.Burst(), 1))
+}
+
+type writer struct {
+	ctx     context.Context
+	w       io.WriteCloser
+	limiter *rate.Limiter
+}
+
+func newWriter(ctx context.Context, w io.WriteCloser, l *rate.Limiter) *writer {
+	return &writer{ctx: ctx, w: w, limiter: l}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := chunk(w.limiter, len(p))
+		if err := w.limiter.WaitN(w.ctx, n); err != nil {
+			return written, waitError(w.ctx, err)
+		}
+		m, err := w.w.Write(p[:n])
+		written += m
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// Sync syncs the wrapped writer if it supports it
+func (w *writer) Sync() error {
+	return storage.Sync(w.w)
+}
+
+func (w *writer) Close() error {
+	return w.w.Close()
+}
+
+type reader struct {
+	ctx     context.Context
+	r       io.ReadCloser
+	limiter *rate.Limiter
+}
+
+// newReader returns a throttled reader that implements io.Seeker if r does
+func newReader(ctx context.Context, r io.ReadCloser, l *rate.Limiter) io.ReadCloser {
+	rr := &reader{ctx: ctx, r: r, limiter: l}
+	if s, ok := r.(io.Seeker); ok {
+		return &seekReader{reader: rr, s: s}
+	}
+	return rr
+}
+
+// Read reads at most one burst and then waits for the tokens of the bytes
+// actually read, so short reads are not overcharged
+func (r *reader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.r.Read(p[:chunk(r.limiter, len(p))])
+	if n > 0 {
+		if werr := r.limiter.WaitN(r.ctx, n); werr != nil {
+			return n, waitError(r.ctx, werr)
+		}
+	}
+	return n, err
+}
+
+func (r *reader) Close() error {
+	return r.r.Close()
+}
+
+// seekReader is a reader over a seekable stream. Seeking is not throttled.
+type seekReader struct {
+	*reader
+	s io.Seeker
+}
+
+func (r *seekReader) Seek(offset int64, whence int) (int64, error) {
+	return r.s.Seek(offset, whence)
+}
+
+// waitError prefers the context error over the limiter's own error.
+// The limiter fails early if the deadline would expire while waiting.
+func waitError(ctx context.Context, err error) error {
+	if cerr := ctx.Err(); cerr != nil {
+		return cerr
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return context.DeadlineExceeded
+	}
+	return err
+}
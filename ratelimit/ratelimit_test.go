@@ -0,0 +1,143 @@
+package ratelimit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/memory"
+	"schneider.vip/hybridbuffer/storage/ratelimit"
+	"schneider.vip/hybridbuffer/storage/storagetest"
+)
+
+func TestSuite(t *testing.T) {
+	storagetest.RunSuite(t, func() storage.Backend {
+		return ratelimit.Wrap(memory.New(), 1<<30)
+	})
+}
+
+// The limiter starts with a full burst of 1000 bytes, so 3000 bytes at
+// 10000 bytes per second need at least 200ms
+const (
+	bytesPerSec = 10000
+	burst       = 1000
+	payload     = 3000
+	minDuration = 150 * time.Millisecond
+)
+
+func TestThrottlesWrites(t *testing.T) {
+	b := ratelimit.Wrap(memory.New(), bytesPerSec, ratelimit.WithBurst(burst))
+	start := time.Now()
+	writePayload(t, b, payload)
+	if d := time.Since(start); d < minDuration {
+		t.Fatalf("writing took %v, want at least %v", d, minDuration)
+	}
+}
+
+func TestThrottlesReads(t *testing.T) {
+	inner := memory.New()
+	writePayload(t, inner, payload)
+
+	b := ratelimit.Wrap(inner, bytesPerSec, ratelimit.WithBurst(burst))
+	r, err := b.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	start := time.Now()
+	n, err := io.Copy(io.Discard, r)
+	if err != nil || n != payload {
+		t.Fatalf("got %d bytes, %v, want %d bytes", n, err, payload)
+	}
+	if d := time.Since(start); d < minDuration {
+		t.Fatalf("reading took %v, want at least %v", d, minDuration)
+	}
+}
+
+func TestSharedLimiter(t *testing.T) {
+	l := rate.NewLimiter(bytesPerSec, burst)
+	a := ratelimit.Wrap(memory.New(), 0, ratelimit.WithLimiter(l))
+	b := ratelimit.Wrap(memory.New(), 0, ratelimit.WithLimiter(l))
+
+	// Each backend alone would fit into the burst plus 50ms
+	start := time.Now()
+	writePayload(t, a, payload/2)
+	writePayload(t, b, payload/2)
+	if d := time.Since(start); d < minDuration {
+		t.Fatalf("writing took %v, want at least %v", d, minDuration)
+	}
+}
+
+func TestWaitInterruptedByContext(t *testing.T) {
+	b := ratelimit.Wrap(memory.New(), 100, ratelimit.WithBurst(100))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	w, err := storage.CreateContext(ctx, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	_, err = w.Write(make([]byte, 1000))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Write: got %v, want context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("Write returned after %v, want it to stop at the deadline", d)
+	}
+	w.Close()
+}
+
+func TestCreateContextCancelledMidWrite(t *testing.T) {
+	b := ratelimit.Wrap(memory.New(), 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	w, err := storage.CreateContext(ctx, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, "partial"); err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	if _, err := io.WriteString(w, "more"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Write: got %v, want context.Canceled", err)
+	}
+	if err := w.Close(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Close: got %v, want context.Canceled", err)
+	}
+	if ok, err := storage.Exists(b); ok || err != nil {
+		t.Fatalf("Exists after cancel: got %v, %v, want false", ok, err)
+	}
+}
+
+func TestOpenSeekable(t *testing.T) {
+	b := ratelimit.Wrap(memory.New(), 0)
+	writePayload(t, b, 10)
+	r, err := storage.OpenSeeker(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+}
+
+func writePayload(t *testing.T, b storage.Backend, n int) {
+	t.Helper()
+	w, err := b.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(bytes.Repeat([]byte{'x'}, n)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,151 @@
+// Package readahead provides a storage backend wrapper whose readers
+// prefetch the next chunk in the background while the caller consumes
+// the current one
+package readahead
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+// DefaultBufSize is the chunk size used when Wrap is called with bufSize <= 0
+const DefaultBufSize = 1 << 20
+
+var errClosed = errors.New("readahead: read on closed reader")
+
+type backend struct {
+	inner   storage.Backend
+	bufSize int
+}
+
+// Wrap returns a backend whose Open prefetches chunks of bufSize bytes
+// from b. Create and Remove pass through unchanged.
+//
+// Closing a reader early closes the wrapped reader while a prefetch may be
+// in flight to unblock it, so the wrapped reader's Close must be safe to
+// call concurrently with Read, as it is for files and network streams.
+func Wrap(b storage.Backend, bufSize int) storage.Backend {
+	if bufSize <= 0 {
+		bufSize = DefaultBufSize
+	}
+	return &backend{inner: b, bufSize: bufSize}
+}
+
+// Create creates the wrapped location
+func (b *backend) Create() (io.WriteCloser, error) {
+	return b.inner.Create()
+}
+
+// Open returns a reader that prefetches from the wrapped location
+func (b *backend) Open() (io.ReadCloser, error) {
+	r, err := b.inner.Open()
+	if err != nil {
+		return nil, err
+	}
+	return newReader(r, b.bufSize), nil
+}
+
+// Remove removes the wrapped location
+func (b *backend) Remove() error {
+	return b.inner.Remove()
+}
+
+type chunk struct {
+	buf []byte
+	n   int
+	err error
+}
+
+// reader double-buffers: one chunk is consumed by Read while the
+// background goroutine fills the other
+type reader struct {
+	r      io.ReadCloser
+	chunks chan chunk
+	free   chan []byte
+	done   chan struct{}
+	exited chan struct{}
+
+	cur    []byte
+	curBuf []byte
+	err    error
+
+	closeOnce sync.Once
+	closeErr  error
+	closed    bool
+}
+
+func newReader(r io.ReadCloser, bufSize int) *reader {
+	ra := &reader{
+		r:      r,
+		chunks: make(chan chunk, 1),
+		free:   make(chan []byte, 2),
+		done:   make(chan struct{}),
+		exited: make(chan struct{}),
+	}
+	ra.free <- make([]byte, bufSize)
+	ra.free <- make([]byte, bufSize)
+	go ra.fill()
+	return ra
+}
+
+// fill reads chunks until the stream ends, fails or the reader is closed
+func (r *reader) fill() {
+	defer close(r.exited)
+	for {
+		var buf []byte
+		select {
+		case buf = <-r.free:
+		case <-r.done:
+			return
+		}
+
+		n, err := io.ReadFull(r.r, buf)
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		select {
+		case r.chunks <- chunk{buf: buf, n: n, err: err}:
+		case <-r.done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Read returns prefetched data. Errors of the background fetch are
+// returned once the data read before the error has been consumed.
+func (r *reader) Read(p []byte) (int, error) {
+	if r.closed {
+		return 0, errClosed
+	}
+	for len(r.cur) == 0 {
+		if r.curBuf != nil {
+			r.free <- r.curBuf
+			r.curBuf = nil
+		}
+		if r.err != nil {
+			return 0, r.err
+		}
+		c := <-r.chunks
+		r.cur, r.curBuf, r.err = c.buf[:c.n], c.buf, c.err
+	}
+	n := copy(p, r.cur)
+	r.cur = r.cur[n:]
+	return n, nil
+}
+
+// Close stops the background goroutine and closes the wrapped reader
+func (r *reader) Close() error {
+	r.closeOnce.Do(func() {
+		r.closed = true
+		close(r.done)
+		r.closeErr = r.r.Close()
+		<-r.exited
+	})
+	return r.closeErr
+}
@@ -0,0 +1,112 @@
+package readahead_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/memory"
+	"schneider.vip/hybridbuffer/storage/readahead"
+	"schneider.vip/hybridbuffer/storage/storagetest"
+)
+
+func TestSuite(t *testing.T) {
+	storagetest.RunSuite(t, func() storage.Backend {
+		return readahead.Wrap(memory.New(), 64<<10)
+	})
+}
+
+// opener is a backend whose Open returns a fixed reader
+type opener struct {
+	storage.Backend
+	r io.ReadCloser
+}
+
+func (o opener) Open() (io.ReadCloser, error) {
+	return o.r, nil
+}
+
+func TestEarlyCloseUnblocksPrefetch(t *testing.T) {
+	// Nothing is ever written, so the prefetch blocks until Close
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	b := readahead.Wrap(opener{Backend: memory.New(), r: pr}, 4)
+	r, err := b.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- r.Close() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return while a prefetch was blocked")
+	}
+
+	if _, err := pw.Write([]byte("x")); !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("wrapped reader was not closed: %v", err)
+	}
+	if _, err := r.Read(make([]byte, 1)); err == nil {
+		t.Fatal("Read after Close succeeded")
+	}
+}
+
+func TestEarlyCloseAfterPartialRead(t *testing.T) {
+	inner := memory.New()
+	w, _ := inner.Create()
+	io.WriteString(w, strings.Repeat("x", 100))
+	w.Close()
+
+	r, err := readahead.Wrap(inner, 8).Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadFull(r, make([]byte, 10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+}
+
+// failingReader returns data followed by err
+type failingReader struct {
+	data string
+	err  error
+}
+
+func (f *failingReader) Read(p []byte) (int, error) {
+	if f.data == "" {
+		return 0, f.err
+	}
+	n := copy(p, f.data)
+	f.data = f.data[n:]
+	return n, nil
+}
+
+func (f *failingReader) Close() error { return nil }
+
+func TestErrorAfterData(t *testing.T) {
+	errBroken := errors.New("connection reset")
+	inner := opener{Backend: memory.New(), r: &failingReader{data: "0123456789", err: errBroken}}
+	r, err := readahead.Wrap(inner, 4).Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if string(data) != "0123456789" || !errors.Is(err, errBroken) {
+		t.Fatalf("got %q, %v, want all data and then the error", data, err)
+	}
+}
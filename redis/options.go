@@ -0,0 +1,36 @@
+package redis
+
+import "time"
+
+// DefaultMaxSize is the largest value Redis accepts for a string key
+const DefaultMaxSize = 512 << 20
+
+// Option configures the Redis backend
+type Option func(*options)
+
+type options struct {
+	ttl     time.Duration
+	maxSize int64
+}
+
+func defaultOptions() options {
+	return options{
+		maxSize: DefaultMaxSize,
+	}
+}
+
+// WithTTL sets an expiry on the stored key so abandoned spills are
+// dropped by Redis. Zero, the default, disables expiry.
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+// WithMaxSize sets the largest value the writer accepts (default
+// DefaultMaxSize). Larger values fail on Close with ErrValueTooLarge.
+func WithMaxSize(n int64) Option {
+	return func(o *options) {
+		o.maxSize = n
+	}
+}
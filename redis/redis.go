@@ -0,0 +1,164 @@
+// Package redis provides a storage backend that keeps data in a single
+// Redis key. Values are held fully in memory on both sides, so it is
+// meant for small, short-lived spills.
+//
+// Backend methods are safe for concurrent use. The value is only set when
+// a writer is closed, so Open during a write sees the previous value, if any.
+package redis
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+// ErrValueTooLarge is returned by the writer's Close when more data was
+// written than the configured maximum size
+var ErrValueTooLarge = errors.New("redis: value exceeds maximum size")
+
+type backend struct {
+	client redis.UniversalClient
+	key    string
+	opts   options
+}
+
+// New creates a Redis backend storing its data under key
+func New(client redis.UniversalClient, key string, opts ...Option) storage.Backend {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &backend{client: client, key: key, opts: o}
+}
+
+// Create returns a writer that buffers the value and stores it on Close
+func (b *backend) Create() (io.WriteCloser, error) {
+	return b.CreateContext(context.Background())
+}
+
+// CreateContext returns a writer that buffers the value and stores it on
+// Close using ctx
+func (b *backend) CreateContext(ctx context.Context) (io.WriteCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &writer{ctx: ctx, b: b, ttl: b.opts.ttl}, nil
+}
+
+// CreateWithTTL returns a writer like Create whose value expires ttl
+// after Close. It overrides the WithTTL option; a ttl of zero means no
+// expiry.
+func (b *backend) CreateWithTTL(ttl time.Duration) (io.WriteCloser, error) {
+	return &writer{ctx: context.Background(), b: b, ttl: max(ttl, 0)}, nil
+}
+
+// Open fetches the value and returns a reader over it
+func (b *backend) Open() (io.ReadCloser, error) {
+	return b.OpenContext(context.Background())
+}
+
+// OpenContext fetches the value using ctx and returns a reader over it
+func (b *backend) OpenContext(ctx context.Context) (io.ReadCloser, error) {
+	data, err := b.client.Get(ctx, b.key).Bytes()
+	if err != nil {
+		return nil, mapError("get", err)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Remove deletes the key
+func (b *backend) Remove() error {
+	return b.RemoveContext(context.Background())
+}
+
+// RemoveContext deletes the key using ctx
+func (b *backend) RemoveContext(ctx context.Context) error {
+	if err := b.client.Del(ctx, b.key).Err(); err != nil {
+		return mapError("del", err)
+	}
+	return nil
+}
+
+// Size returns the length of the stored value
+func (b *backend) Size() (int64, error) {
+	ok, err := b.Exists()
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("redis: strlen: %w", storage.ErrNotFound)
+	}
+	n, err := b.client.StrLen(context.Background(), b.key).Result()
+	if err != nil {
+		return 0, mapError("strlen", err)
+	}
+	return n, nil
+}
+
+// Exists reports whether the key exists
+func (b *backend) Exists() (bool, error) {
+	n, err := b.client.Exists(context.Background(), b.key).Result()
+	if err != nil {
+		return false, mapError("exists", err)
+	}
+	return n > 0, nil
+}
+
+type writer struct {
+	ctx      context.Context
+	b        *backend
+	ttl      time.Duration
+	buf      bytes.Buffer
+	n        int64
+	tooLarge bool
+	closed   bool
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("redis: write on closed writer")
+	}
+	w.n += int64(len(p))
+	if w.n > w.b.opts.maxSize {
+		// Keep accepting writes but stop buffering; Close reports the error
+		w.tooLarge = true
+		w.buf = bytes.Buffer{}
+		return len(p), nil
+	}
+	return w.buf.Write(p)
+}
+
+// Close stores the buffered value
+func (w *writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.tooLarge {
+		return fmt.Errorf("redis: set %s: %d bytes: %w", w.b.key, w.n, ErrValueTooLarge)
+	}
+	if err := w.b.client.Set(w.ctx, w.b.key, w.buf.Bytes(), w.ttl).Err(); err != nil {
+		return mapError("set", err)
+	}
+	return nil
+}
+
+// mapError wraps err and maps Redis failures onto the storage sentinel errors
+func mapError(op string, err error) error {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, redis.Nil):
+		return fmt.Errorf("redis: %s: %w", op, storage.ErrNotFound)
+	case errors.As(err, &netErr):
+		return fmt.Errorf("redis: %s: %w: %w", op, storage.ErrUnavailable, err)
+	}
+	return fmt.Errorf("redis: %s: %w", op, err)
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FactoryFunc creates a backend from a parsed storage URL
+type FactoryFunc func(u *url.URL) (Backend, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]FactoryFunc{}
+)
+
+// Register makes a backend factory available for URLs with the given
+// scheme. Backend subpackages register themselves from init, so importing
+// them is enough to enable their scheme. Registering a scheme twice
+// replaces the earlier factory.
+func Register(scheme string, f FactoryFunc) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[strings.ToLower(scheme)] = f
+}
+
+// Open creates a backend from a URL such as file:///tmp/spill or
+// s3://bucket/key using the factory registered for its scheme
+func Open(rawurl string) (Backend, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, fmt.Errorf("storage: parse url: %w", err)
+	}
+
+	registryMu.RLock()
+	f, ok := registry[strings.ToLower(u.Scheme)]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("storage: unknown scheme %q (registered: %s)", u.Scheme, strings.Join(Schemes(), ", "))
+	}
+
+	b, err := f(u)
+	if err != nil {
+		return nil, fmt.Errorf("storage: open %s: %w", u.Redacted(), err)
+	}
+	return b, nil
+}
+
+// Schemes returns the registered URL schemes in sorted order
+func Schemes() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	schemes := make([]string, 0, len(registry))
+	for s := range registry {
+		schemes = append(schemes, s)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
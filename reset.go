@@ -0,0 +1,19 @@
+package storage
+
+// Resettable is implemented by backends that can be recycled for another
+// spill, e.g. through a sync.Pool
+type Resettable interface {
+	// Reset removes the stored data and prepares the backend for a new
+	// Create, possibly under a different name. Calling Reset while a
+	// reader or writer of the backend is open is undefined.
+	Reset() error
+}
+
+// Reset clears b so it can be reused for another spill. Backends that do
+// not implement Resettable are reset with Remove.
+func Reset(b Backend) error {
+	if r, ok := b.(Resettable); ok {
+		return r.Reset()
+	}
+	return b.Remove()
+}
@@ -0,0 +1,74 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+// RetryableFunc reports whether an operation failing with err should be retried
+type RetryableFunc func(err error) bool
+
+// Option configures the retrying wrapper
+type Option func(*options)
+
+type options struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	retryable   RetryableFunc
+}
+
+func defaultOptions() options {
+	return options{
+		maxAttempts: 3,
+		baseDelay:   100 * time.Millisecond,
+		maxDelay:    5 * time.Second,
+		retryable:   DefaultRetryable,
+	}
+}
+
+// DefaultRetryable retries every error except missing or existing
+// locations, denied permissions and context cancellation
+func DefaultRetryable(err error) bool {
+	switch {
+	case errors.Is(err, storage.ErrNotFound),
+		errors.Is(err, storage.ErrAlreadyExists),
+		errors.Is(err, storage.ErrPermission),
+		errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded):
+		return false
+	}
+	return true
+}
+
+// WithMaxAttempts sets the total number of attempts including the first one
+func WithMaxAttempts(n int) Option {
+	return func(o *options) {
+		o.maxAttempts = max(n, 1)
+	}
+}
+
+// WithBaseDelay sets the delay before the first retry. The delay doubles
+// with every further attempt.
+func WithBaseDelay(d time.Duration) Option {
+	return func(o *options) {
+		o.baseDelay = d
+	}
+}
+
+// WithMaxDelay caps the delay between attempts
+func WithMaxDelay(d time.Duration) Option {
+	return func(o *options) {
+		o.maxDelay = d
+	}
+}
+
+// WithRetryable sets the predicate deciding which errors are retried
+func WithRetryable(f RetryableFunc) Option {
+	return func(o *options) {
+		o.retryable = f
+	}
+}
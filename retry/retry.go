@@ -0,0 +1,117 @@
+// Package retry provides a storage backend wrapper that retries failed
+// operations with exponential backoff
+package retry
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"time"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+type backend struct {
+	inner storage.Backend
+	opts  options
+}
+
+// Wrap returns a backend that retries Create, Open and Remove on b.
+// Only establishing a stream is retried; errors on the returned readers
+// and writers are passed through unchanged.
+func Wrap(b storage.Backend, opts ...Option) storage.Backend {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &backend{inner: b, opts: o}
+}
+
+// Create retries Create on the wrapped backend and returns its writer
+// unwrapped
+func (b *backend) Create() (io.WriteCloser, error) {
+	var w io.WriteCloser
+	err := b.do(context.Background(), func() (err error) {
+		w, err = b.inner.Create()
+		return err
+	})
+	return w, err
+}
+
+// CreateContext retries Create on the wrapped backend until ctx is done.
+// The writer is bound to ctx with storage.CreateContext.
+func (b *backend) CreateContext(ctx context.Context) (io.WriteCloser, error) {
+	var w io.WriteCloser
+	err := b.do(ctx, func() (err error) {
+		w, err = storage.CreateContext(ctx, b.inner)
+		return err
+	})
+	return w, err
+}
+
+// Open retries Open on the wrapped backend and returns its reader unwrapped
+func (b *backend) Open() (io.ReadCloser, error) {
+	var r io.ReadCloser
+	err := b.do(context.Background(), func() (err error) {
+		r, err = b.inner.Open()
+		return err
+	})
+	return r, err
+}
+
+// OpenContext retries Open on the wrapped backend until ctx is done.
+// The reader is bound to ctx with storage.OpenContext.
+func (b *backend) OpenContext(ctx context.Context) (io.ReadCloser, error) {
+	var r io.ReadCloser
+	err := b.do(ctx, func() (err error) {
+		r, err = storage.OpenContext(ctx, b.inner)
+		return err
+	})
+	return r, err
+}
+
+// Remove retries Remove on the wrapped backend
+func (b *backend) Remove() error {
+	return b.RemoveContext(context.Background())
+}
+
+// RemoveContext retries Remove on the wrapped backend until ctx is done
+func (b *backend) RemoveContext(ctx context.Context) error {
+	return b.do(ctx, func() error {
+		return storage.RemoveContext(ctx, b.inner)
+	})
+}
+
+func (b *backend) do(ctx context.Context, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt+1 >= b.opts.maxAttempts || !b.opts.retryable(err) {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		t := time.NewTimer(b.delay(attempt))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// delay returns a random delay up to the exponential backoff for attempt
+func (b *backend) delay(attempt int) time.Duration {
+	d := b.opts.maxDelay
+	if attempt < 62 {
+		if exp := b.opts.baseDelay << attempt; exp>>attempt == b.opts.baseDelay && exp < d {
+			d = exp
+		}
+	}
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d)
+}
@@ -0,0 +1,180 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/filesystem"
+	"schneider.vip/hybridbuffer/storage/memory"
+	"schneider.vip/hybridbuffer/storage/retry"
+	"schneider.vip/hybridbuffer/storage/storagetest"
+)
+
+func TestSuite(t *testing.T) {
+	storagetest.RunSuite(t, func() storage.Backend {
+		return retry.Wrap(memory.New())
+	})
+}
+
+func TestCreateContextCancelledMidWrite(t *testing.T) {
+	b := retry.Wrap(filesystem.New(t.TempDir()))
+	ctx, cancel := context.WithCancel(context.Background())
+	w, err := storage.CreateContext(ctx, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := w.(storage.Syncer); !ok {
+		t.Error("writer does not implement storage.Syncer")
+	}
+	if _, err := io.WriteString(w, "partial"); err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	if _, err := io.WriteString(w, "more"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Write: got %v, want context.Canceled", err)
+	}
+	if err := w.Close(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Close: got %v, want context.Canceled", err)
+	}
+	if ok, err := storage.Exists(b); ok || err != nil {
+		t.Fatalf("Exists after cancel: got %v, %v, want false", ok, err)
+	}
+}
+
+func TestOpenContextSeekable(t *testing.T) {
+	b := retry.Wrap(memory.New())
+	w, _ := b.Create()
+	io.WriteString(w, "hello")
+	w.Close()
+
+	r, err := storage.OpenContext(context.Background(), b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	s, ok := r.(io.Seeker)
+	if !ok {
+		t.Fatal("reader does not implement io.Seeker")
+	}
+	if _, err := s.Seek(2, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(r); string(data) != "llo" {
+		t.Fatalf("got %q, want %q", data, "llo")
+	}
+}
+
+// flaky fails the first failures calls of Create, Open and Remove with err
+type flaky struct {
+	storage.Backend
+	err      error
+	failures int
+	calls    int
+}
+
+func (f *flaky) fail() error {
+	f.calls++
+	if f.calls <= f.failures {
+		return f.err
+	}
+	return nil
+}
+
+func (f *flaky) Create() (io.WriteCloser, error) {
+	if err := f.fail(); err != nil {
+		return nil, err
+	}
+	return f.Backend.Create()
+}
+
+func (f *flaky) Open() (io.ReadCloser, error) {
+	if err := f.fail(); err != nil {
+		return nil, err
+	}
+	return f.Backend.Open()
+}
+
+func (f *flaky) Remove() error {
+	if err := f.fail(); err != nil {
+		return err
+	}
+	return f.Backend.Remove()
+}
+
+var errTransient = fmt.Errorf("transient: %w", storage.ErrUnavailable)
+
+func TestRetriesUntilSuccess(t *testing.T) {
+	f := &flaky{Backend: memory.New(), err: errTransient, failures: 2}
+	b := retry.Wrap(f, retry.WithMaxAttempts(3), retry.WithBaseDelay(time.Millisecond))
+
+	w, err := b.Create()
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	w.Close()
+	if f.calls != 3 {
+		t.Fatalf("got %d attempts, want 3", f.calls)
+	}
+}
+
+func TestGivesUpAfterMaxAttempts(t *testing.T) {
+	f := &flaky{Backend: memory.New(), err: errTransient, failures: 10}
+	b := retry.Wrap(f, retry.WithMaxAttempts(4), retry.WithBaseDelay(time.Millisecond))
+
+	if err := b.Remove(); !errors.Is(err, errTransient) {
+		t.Fatalf("got %v, want the last error", err)
+	}
+	if f.calls != 4 {
+		t.Fatalf("got %d attempts, want 4", f.calls)
+	}
+}
+
+func TestDoesNotRetryPermanentErrors(t *testing.T) {
+	for _, err := range []error{storage.ErrNotFound, storage.ErrPermission, storage.ErrAlreadyExists} {
+		f := &flaky{Backend: memory.New(), err: err, failures: 10}
+		b := retry.Wrap(f, retry.WithBaseDelay(time.Millisecond))
+		if _, got := b.Open(); !errors.Is(got, err) {
+			t.Fatalf("got %v, want %v", got, err)
+		}
+		if f.calls != 1 {
+			t.Fatalf("%v: got %d attempts, want 1", err, f.calls)
+		}
+	}
+}
+
+func TestRetryablePredicate(t *testing.T) {
+	f := &flaky{Backend: memory.New(), err: storage.ErrNotFound, failures: 2}
+	retryAll := func(error) bool { return true }
+	b := retry.Wrap(f, retry.WithRetryable(retryAll), retry.WithBaseDelay(time.Millisecond))
+
+	if err := b.Remove(); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if f.calls != 3 {
+		t.Fatalf("got %d attempts, want 3", f.calls)
+	}
+}
+
+func TestContextStopsRetrying(t *testing.T) {
+	f := &flaky{Backend: memory.New(), err: errTransient, failures: 100}
+	b := retry.Wrap(f, retry.WithMaxAttempts(100), retry.WithBaseDelay(time.Hour), retry.WithMaxDelay(time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := storage.CreateContext(ctx, b); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("CreateContext returned after %v, want it to stop at the deadline", d)
+	}
+	if f.calls >= 100 {
+		t.Fatalf("got %d attempts, want retrying to stop", f.calls)
+	}
+}
@@ -0,0 +1,151 @@
+package s3_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/memory"
+	"schneider.vip/hybridbuffer/storage/s3"
+	"schneider.vip/hybridbuffer/storage/storagetest"
+)
+
+// fakeS3 serves PutObject, GetObject, HeadObject and DeleteObject for
+// path-style requests, which is all a single-part copy needs
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.objects[r.URL.Path] = data
+	case http.MethodGet, http.MethodHead:
+		data, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "<Error><Code>NoSuchKey</Code></Error>")
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// reads records the buffer size of every Read
+type reads struct {
+	r     io.Reader
+	sizes []int
+}
+
+func (r *reads) Read(p []byte) (int, error) {
+	r.sizes = append(r.sizes, len(p))
+	return r.r.Read(p)
+}
+
+func (r *reads) Close() error { return nil }
+
+// source is a backend whose reader is r
+type source struct {
+	storage.Backend
+	r *reads
+}
+
+func (s source) Open() (io.ReadCloser, error) { return s.r, nil }
+
+func TestCopyReadFrom(t *testing.T) {
+	srv := httptest.NewServer(&fakeS3{objects: make(map[string][]byte)})
+	t.Cleanup(srv.Close)
+	obj := s3.New(newClient(), "bucket", "object", s3.WithEndpoint(srv.URL), s3.WithPathStyle(true))
+	data := bytes.Repeat([]byte("x"), 100<<10)
+
+	// The writer's ReadFrom reads straight into the part buffer
+	fast := &reads{r: bytes.NewReader(data)}
+	if _, err := storage.Copy(obj, source{memory.New(), fast}); err != nil {
+		t.Fatal(err)
+	}
+	if fast.sizes[0] < s3.MinPartSize {
+		t.Fatalf("fast path read into a %d byte buffer, want the part buffer", fast.sizes[0])
+	}
+
+	buffered := &reads{r: bytes.NewReader(data)}
+	if _, err := storage.Copy(storagetest.Plain(obj), source{memory.New(), buffered}); err != nil {
+		t.Fatal(err)
+	}
+	if buffered.sizes[0] >= s3.MinPartSize {
+		t.Fatalf("buffered copy read into a %d byte buffer", buffered.sizes[0])
+	}
+
+	r, err := obj.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if got, _ := io.ReadAll(r); !bytes.Equal(got, data) {
+		t.Fatalf("object holds %d bytes, want the %d bytes copied", len(got), len(data))
+	}
+}
+
+func BenchmarkCopy(b *testing.B) {
+	srv := httptest.NewServer(&fakeS3{objects: make(map[string][]byte)})
+	b.Cleanup(srv.Close)
+
+	client := newClient()
+	opts := []s3.Option{s3.WithEndpoint(srv.URL), s3.WithPathStyle(true)}
+
+	// Stay below the part size so every copy is a single PutObject
+	data := bytes.Repeat([]byte("hybridbuffer"), 4<<20/12)
+	mem := memory.New()
+	w, _ := mem.Create()
+	w.Write(data)
+	w.Close()
+
+	obj := s3.New(client, "bucket", "object", opts...)
+	if _, err := storage.Copy(obj, mem); err != nil {
+		b.Fatal(err)
+	}
+
+	run := func(b *testing.B, dst, src storage.Backend) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for b.Loop() {
+			if _, err := storage.Copy(dst, src); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	// The source hides its io.WriterTo so that the writer's ReadFrom is used
+	b.Run("Upload/FastPath", func(b *testing.B) {
+		run(b, s3.New(client, "bucket", "upload", opts...), storagetest.Plain(mem))
+	})
+	b.Run("Upload/Buffered", func(b *testing.B) {
+		run(b, storagetest.Plain(s3.New(client, "bucket", "upload", opts...)), storagetest.Plain(mem))
+	})
+	b.Run("Download/FastPath", func(b *testing.B) {
+		run(b, memory.New(), obj)
+	})
+	b.Run("Download/Buffered", func(b *testing.B) {
+		run(b, storagetest.Plain(memory.New()), storagetest.Plain(obj))
+	})
+}
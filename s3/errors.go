@@ -0,0 +1,49 @@
+package s3
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/aws/smithy-go"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+// mapError wraps err and maps S3 failures onto the storage sentinel errors
+func mapError(op string, err error) error {
+	if sentinel := classify(err); sentinel != nil {
+		return fmt.Errorf("s3: %s: %w: %w", op, sentinel, err)
+	}
+	return fmt.Errorf("s3: %s: %w", op, err)
+}
+
+func classify(err error) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "NoSuchKey", "NoSuchBucket", "NotFound":
+			return storage.ErrNotFound
+		case "AccessDenied", "Forbidden", "InvalidAccessKeyId", "SignatureDoesNotMatch":
+			return storage.ErrPermission
+		case "PreconditionFailed":
+			return storage.ErrAlreadyExists
+		case "ServiceUnavailable", "SlowDown", "InternalError", "RequestTimeout":
+			return storage.ErrUnavailable
+		}
+		return nil
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return storage.ErrUnavailable
+	}
+	return nil
+}
+
+// isInvalidRange reports whether err rejects a range starting beyond the
+// end of the object
+func isInvalidRange(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "InvalidRange"
+}
@@ -0,0 +1,57 @@
+//go:build integration
+
+package s3_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/testcontainers/testcontainers-go/modules/minio"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/s3"
+	"schneider.vip/hybridbuffer/storage/storagetest"
+)
+
+const bucket = "hybridbuffer"
+
+// TestMinIO runs the conformance suite against a MinIO container using
+// path-style addressing. It needs Docker:
+//
+//	go test -tags integration ./s3
+func TestMinIO(t *testing.T) {
+	ctx := context.Background()
+	container, err := minio.Run(ctx, "minio/minio:RELEASE.2024-01-16T16-07-38Z")
+	if err != nil {
+		t.Fatalf("start minio: %v", err)
+	}
+	t.Cleanup(func() { container.Terminate(context.Background()) })
+
+	addr, err := container.ConnectionString(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint := "http://" + addr
+
+	client := awss3.New(awss3.Options{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider(container.Username, container.Password, ""),
+	})
+	opts := []s3.Option{s3.WithEndpoint(endpoint), s3.WithPathStyle(true)}
+	if _, err := client.CreateBucket(ctx, &awss3.CreateBucketInput{Bucket: aws.String(bucket)}, func(o *awss3.Options) {
+		o.BaseEndpoint = aws.String(endpoint)
+		o.UsePathStyle = true
+	}); err != nil {
+		t.Fatalf("create bucket: %v", err)
+	}
+
+	var n int
+	storagetest.RunSuite(t, func() storage.Backend {
+		n++
+		return s3.New(client, bucket, fmt.Sprintf("suite/%d", n), opts...)
+	})
+}
@@ -0,0 +1,25 @@
+package s3
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+type locator struct {
+	client *s3.Client
+	bucket string
+	prefix string
+	opts   []Option
+}
+
+// NewLocator returns a Locator whose locations are objects in bucket
+// with keys made of prefix followed by the location name
+func NewLocator(client *s3.Client, bucket, prefix string, opts ...Option) storage.Locator {
+	return &locator{client: client, bucket: bucket, prefix: prefix, opts: opts}
+}
+
+// Location returns the backend for the object prefix+name
+func (l *locator) Location(name string) storage.Backend {
+	return New(l.client, l.bucket, l.prefix+name, l.opts...)
+}
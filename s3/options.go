@@ -0,0 +1,147 @@
+package s3
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	// MinPartSize is the smallest part size accepted by S3 multipart uploads
+	MinPartSize = 5 << 20
+
+	// DefaultPartSize is the part size used when none is configured
+	DefaultPartSize = 8 << 20
+
+	// DefaultExpiryTag is the object tag set by CreateWithTTL
+	DefaultExpiryTag = "hybridbuffer-expire-days"
+)
+
+// Option configures the S3 backend
+type Option func(*options)
+
+type options struct {
+	sse          types.ServerSideEncryption
+	sseKMSKeyID  string
+	storageClass types.StorageClass
+	contentType  string
+	expiryTag    string
+	partSize     int64
+
+	// apiOptions are applied to every request, e.g. to reach
+	// S3-compatible stores such as MinIO
+	apiOptions []func(*s3.Options)
+
+	createTimeout time.Duration
+	openTimeout   time.Duration
+	removeTimeout time.Duration
+}
+
+func defaultOptions() options {
+	return options{
+		partSize:  DefaultPartSize,
+		expiryTag: DefaultExpiryTag,
+	}
+}
+
+// WithServerSideEncryption sets the server-side encryption for uploaded
+// objects. kmsKeyID is only used with aws:kms encryption and may be empty.
+func WithServerSideEncryption(sse types.ServerSideEncryption, kmsKeyID string) Option {
+	return func(o *options) {
+		o.sse = sse
+		o.sseKMSKeyID = kmsKeyID
+	}
+}
+
+// WithStorageClass sets the storage class for uploaded objects
+func WithStorageClass(class types.StorageClass) Option {
+	return func(o *options) {
+		o.storageClass = class
+	}
+}
+
+// WithContentType sets the Content-Type of uploaded objects
+func WithContentType(contentType string) Option {
+	return func(o *options) {
+		o.contentType = contentType
+	}
+}
+
+// WithExpiryTag sets the key of the object tag that CreateWithTTL uses to
+// request expiry (default DefaultExpiryTag)
+func WithExpiryTag(key string) Option {
+	return func(o *options) {
+		o.expiryTag = key
+	}
+}
+
+// WithPartSize sets the multipart upload part size. Values below
+// MinPartSize are raised to MinPartSize.
+func WithPartSize(size int64) Option {
+	return func(o *options) {
+		o.partSize = max(size, MinPartSize)
+	}
+}
+
+// WithCreateTimeout bounds every request made by a writer: creating,
+// uploading and completing parts, and single PutObject calls.
+//
+// Like the other timeout options this is an operation-level deadline that
+// applies to each SDK request separately, not to the lifetime of a stream.
+func WithCreateTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.createTimeout = d
+	}
+}
+
+// WithOpenTimeout bounds how long a reader waits for a GetObject response
+// and, once the response has arrived, how long each Read may wait for
+// data. It is an idle timeout: a long sequential read is not limited as
+// long as data keeps arriving. It also bounds the HeadObject requests made
+// by Size, Exists and seeking relative to the end. Requests and Reads that
+// hit the timeout fail with an error wrapping context.DeadlineExceeded.
+func WithOpenTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.openTimeout = d
+	}
+}
+
+// WithRemoveTimeout bounds the DeleteObject request made by Remove
+func WithRemoveTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.removeTimeout = d
+	}
+}
+
+// WithEndpoint sends requests to the given base endpoint URL instead of
+// the AWS endpoint, e.g. "http://localhost:9000" for MinIO
+func WithEndpoint(endpoint string) Option {
+	return func(o *options) {
+		o.apiOptions = append(o.apiOptions, func(so *s3.Options) {
+			so.BaseEndpoint = aws.String(endpoint)
+		})
+	}
+}
+
+// WithPathStyle selects path-style addressing (endpoint/bucket/key)
+// instead of virtual-hosted buckets. MinIO and most other S3-compatible
+// stores require it.
+func WithPathStyle(enabled bool) Option {
+	return func(o *options) {
+		o.apiOptions = append(o.apiOptions, func(so *s3.Options) {
+			so.UsePathStyle = enabled
+		})
+	}
+}
+
+// WithRegion overrides the region of the client for requests made by
+// the backend
+func WithRegion(region string) Option {
+	return func(o *options) {
+		o.apiOptions = append(o.apiOptions, func(so *s3.Options) {
+			so.Region = region
+		})
+	}
+}
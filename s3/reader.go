@@ -0,0 +1,215 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+var errReaderClosed = errors.New("s3: read on closed reader")
+
+// reader streams an object and implements Seek by re-issuing ranged
+// GetObject requests whenever the position changes
+type reader struct {
+	ctx context.Context
+	b   *backend
+
+	body   *timedBody
+	pos    int64
+	size   int64
+	closed bool
+}
+
+func (b *backend) newReader(ctx context.Context) (*reader, error) {
+	r := &reader{ctx: ctx, b: b, size: -1}
+	if err := r.openAt(0); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if r.closed {
+		return 0, errReaderClosed
+	}
+	if r.body == nil {
+		if r.size >= 0 && r.pos >= r.size {
+			return 0, io.EOF
+		}
+		if err := r.openAt(r.pos); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.body.Read(p)
+	r.pos += int64(n)
+	if err != nil && err != io.EOF {
+		if cerr := context.Cause(r.body.ctx); cerr != nil {
+			return n, fmt.Errorf("s3: read %s: %w", r.b.key, cerr)
+		}
+	}
+	return n, err
+}
+
+// WriteTo streams the rest of the object directly from the response body
+// to w, avoiding the intermediate buffer of io.Copy
+func (r *reader) WriteTo(w io.Writer) (int64, error) {
+	if r.closed {
+		return 0, errReaderClosed
+	}
+	if r.body == nil {
+		if r.size >= 0 && r.pos >= r.size {
+			return 0, nil
+		}
+		if err := r.openAt(r.pos); err != nil {
+			return 0, err
+		}
+	}
+	n, err := io.Copy(w, r.body)
+	r.pos += n
+	if err != nil {
+		if cerr := context.Cause(r.body.ctx); cerr != nil {
+			return n, fmt.Errorf("s3: read %s: %w", r.b.key, cerr)
+		}
+	}
+	return n, err
+}
+
+// Seek sets the offset for the next Read. Changing the position closes
+// the current response body; the next Read issues a ranged request.
+func (r *reader) Seek(offset int64, whence int) (int64, error) {
+	if r.closed {
+		return 0, errReaderClosed
+	}
+
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = r.pos + offset
+	case io.SeekEnd:
+		if r.size < 0 {
+			out, err := r.b.head(r.ctx)
+			if err != nil {
+				return 0, err
+			}
+			r.size = aws.ToInt64(out.ContentLength)
+		}
+		abs = r.size + offset
+	default:
+		return 0, errors.New("s3: seek: invalid whence")
+	}
+	if abs < 0 {
+		return 0, errors.New("s3: seek: negative position")
+	}
+
+	if abs != r.pos && r.body != nil {
+		r.closeBody()
+	}
+	r.pos = abs
+	return abs, nil
+}
+
+func (r *reader) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if r.body == nil {
+		return nil
+	}
+	return r.closeBody()
+}
+
+func (r *reader) closeBody() error {
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
+
+// openAt issues a GetObject request starting at off
+func (r *reader) openAt(off int64) error {
+	in := &s3.GetObjectInput{
+		Bucket: aws.String(r.b.bucket),
+		Key:    aws.String(r.b.key),
+	}
+	if off > 0 {
+		in.Range = aws.String(fmt.Sprintf("bytes=%d-", off))
+	}
+
+	out, body, err := r.b.getObject(r.ctx, in)
+	if err != nil {
+		return err
+	}
+	if off == 0 && out.ContentLength != nil {
+		r.size = *out.ContentLength
+	}
+	r.body = body
+	return nil
+}
+
+// getObject issues a GetObject request whose response body is bound to
+// the open timeout, see timedBody
+func (b *backend) getObject(ctx context.Context, in *s3.GetObjectInput) (*s3.GetObjectOutput, *timedBody, error) {
+	reqCtx, cancel := context.WithCancelCause(ctx)
+	t := &timedBody{ctx: reqCtx, cancel: cancel, timeout: b.opts.openTimeout}
+	if t.timeout > 0 {
+		t.timer = time.AfterFunc(t.timeout, func() { cancel(context.DeadlineExceeded) })
+	}
+
+	out, err := b.client.GetObject(reqCtx, in, b.opts.apiOptions...)
+	t.pause()
+	if err != nil {
+		if cause := context.Cause(reqCtx); errors.Is(cause, context.DeadlineExceeded) && !errors.Is(err, cause) {
+			err = fmt.Errorf("%w: %w", cause, err)
+		}
+		cancel(nil)
+		return nil, nil, mapError("get object", err)
+	}
+	t.ReadCloser = out.Body
+	return out, t, nil
+}
+
+// timedBody is a response body whose request is cancelled once the open
+// timeout passes while waiting for the response or for a single Read.
+// The timeout bounds how long the stream may stall, not how long it takes
+// to read it to the end.
+type timedBody struct {
+	io.ReadCloser
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func (t *timedBody) Read(p []byte) (int, error) {
+	if t.timer != nil {
+		t.timer.Reset(t.timeout)
+	}
+	n, err := t.ReadCloser.Read(p)
+	t.pause()
+	if err != nil && err != io.EOF {
+		if cause := context.Cause(t.ctx); cause != nil {
+			return n, cause
+		}
+	}
+	return n, err
+}
+
+func (t *timedBody) Close() error {
+	t.pause()
+	err := t.ReadCloser.Close()
+	t.cancel(nil)
+	return err
+}
+
+func (t *timedBody) pause() {
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+}
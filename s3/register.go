@@ -0,0 +1,64 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+func init() {
+	storage.Register("s3", fromURL)
+}
+
+// fromURL creates a backend from
+// s3://bucket/key?region=r&endpoint=e&path_style=true&storage_class=c&part_size=n
+// using the default AWS credential chain
+func fromURL(u *url.URL) (storage.Backend, error) {
+	bucket := u.Host
+	key := strings.TrimPrefix(u.Path, "/")
+	if bucket == "" || key == "" {
+		return nil, errors.New("s3: url must have the form s3://bucket/key")
+	}
+
+	q := u.Query()
+	var cfgOpts []func(*config.LoadOptions) error
+	if v := q.Get("region"); v != "" {
+		cfgOpts = append(cfgOpts, config.WithRegion(v))
+	}
+	cfg, err := config.LoadDefaultConfig(context.Background(), cfgOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("s3: load config: %w", err)
+	}
+
+	var opts []Option
+	if v := q.Get("endpoint"); v != "" {
+		opts = append(opts, WithEndpoint(v))
+	}
+	if v := q.Get("path_style"); v != "" {
+		pathStyle, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("s3: invalid path_style %q: %w", v, err)
+		}
+		opts = append(opts, WithPathStyle(pathStyle))
+	}
+	if v := q.Get("storage_class"); v != "" {
+		opts = append(opts, WithStorageClass(types.StorageClass(v)))
+	}
+	if v := q.Get("part_size"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("s3: invalid part_size %q: %w", v, err)
+		}
+		opts = append(opts, WithPartSize(n))
+	}
+	return New(s3.NewFromConfig(cfg), bucket, key, opts...), nil
+}
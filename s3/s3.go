@@ -0,0 +1,246 @@
+// Package s3 provides a storage backend that keeps data in an Amazon S3
+// object.
+//
+// Backend methods are safe for concurrent use. The object is only
+// replaced when a writer is closed, so Open during a write sees the
+// previous object, if any.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+type backend struct {
+	client *s3.Client
+	bucket string
+	key    string
+	opts   options
+
+	mu       sync.Mutex
+	metadata map[string]string
+}
+
+// New creates an S3 backend storing its data in the object key of bucket
+func New(client *s3.Client, bucket, key string, opts ...Option) storage.Backend {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &backend{client: client, bucket: bucket, key: key, opts: o}
+}
+
+// SetMetadata sets the user metadata stored with objects written by
+// subsequent Create calls
+func (b *backend) SetMetadata(md map[string]string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.metadata = maps.Clone(md)
+}
+
+// currentMetadata returns the metadata for a new upload
+func (b *backend) currentMetadata() map[string]string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.metadata
+}
+
+// Create returns a writer that streams the object via multipart upload
+func (b *backend) Create() (io.WriteCloser, error) {
+	return b.CreateContext(context.Background())
+}
+
+// CreateContext returns a writer that streams the object via multipart
+// upload. The upload is aborted as soon as ctx is cancelled before Close,
+// so no parts are left behind if the writer is abandoned.
+func (b *backend) CreateContext(ctx context.Context) (io.WriteCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	w := newWriter(ctx, b)
+	w.abortOnDone()
+	return w, nil
+}
+
+// CreateSized returns a writer for an object of the given size. Objects
+// smaller than the part size are uploaded with a single PutObject call;
+// writing more than size bytes fails. Other sizes use multipart upload.
+func (b *backend) CreateSized(size int64) (io.WriteCloser, error) {
+	if size < 0 || size >= b.opts.partSize {
+		return b.Create()
+	}
+	return newSingleWriter(context.Background(), b, size), nil
+}
+
+// CreateWithTTL returns a writer like Create that tags the object with
+// the expiry tag set to ttl in whole days, rounded up. S3 does not expire
+// objects by itself: the bucket needs a lifecycle rule that expires
+// objects with the tag, e.g. hybridbuffer-expire-days=1 after one day.
+// A ttl of zero means no expiry and sets no tag.
+func (b *backend) CreateWithTTL(ttl time.Duration) (io.WriteCloser, error) {
+	w := newWriter(context.Background(), b)
+	if ttl > 0 {
+		days := (ttl + 24*time.Hour - 1) / (24 * time.Hour)
+		tags := url.Values{b.opts.expiryTag: {strconv.FormatInt(int64(days), 10)}}
+		w.tagging = aws.String(tags.Encode())
+	}
+	return w, nil
+}
+
+// Append returns a writer that re-uploads the object with data appended.
+// S3 has no native append, so the existing object is first streamed into
+// a new upload, which replaces the object when the writer is closed.
+func (b *backend) Append() (io.WriteCloser, error) {
+	ctx := context.Background()
+	r, err := b.OpenContext(ctx)
+	if errors.Is(err, storage.ErrNotFound) {
+		return b.CreateContext(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	w := newWriter(ctx, b)
+	if _, err := io.Copy(w, r); err != nil {
+		w.fail(err)
+		return nil, fmt.Errorf("s3: append: copy existing object: %w", err)
+	}
+	return w, nil
+}
+
+// Open returns a reader for the object. The reader implements io.Seeker
+// using ranged GetObject requests; seeking backward re-downloads data.
+func (b *backend) Open() (io.ReadCloser, error) {
+	return b.OpenContext(context.Background())
+}
+
+// OpenContext returns a reader for the object bound to ctx
+func (b *backend) OpenContext(ctx context.Context) (io.ReadCloser, error) {
+	r, err := b.newReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// OpenRange returns a reader for length bytes of the object starting at
+// off using a single ranged GetObject request. A length of -1 reads to
+// the end of the object.
+func (b *backend) OpenRange(off, length int64) (io.ReadCloser, error) {
+	if off < 0 || length < -1 {
+		return nil, errors.New("s3: open range: invalid range")
+	}
+	if length == 0 {
+		// An empty range cannot be expressed in a Range header
+		if _, err := b.head(context.Background()); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+
+	rng := fmt.Sprintf("bytes=%d-", off)
+	if length > 0 {
+		rng += strconv.FormatInt(off+length-1, 10)
+	}
+	_, body, err := b.getObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.key),
+		Range:  aws.String(rng),
+	})
+	if isInvalidRange(err) {
+		// The object exists but ends before off
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// Remove deletes the object
+func (b *backend) Remove() error {
+	return b.RemoveContext(context.Background())
+}
+
+// RemoveContext deletes the object bound to ctx
+func (b *backend) RemoveContext(ctx context.Context) error {
+	ctx, cancel := withTimeout(ctx, b.opts.removeTimeout)
+	defer cancel()
+	_, err := b.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.key),
+	}, b.opts.apiOptions...)
+	if err != nil {
+		return mapError("delete object", err)
+	}
+	return nil
+}
+
+// Size returns the object size reported by HeadObject
+func (b *backend) Size() (int64, error) {
+	out, err := b.head(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	return aws.ToInt64(out.ContentLength), nil
+}
+
+// Exists reports whether the object exists using HeadObject
+func (b *backend) Exists() (bool, error) {
+	_, err := b.head(context.Background())
+	if errors.Is(err, storage.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// head issues a HeadObject request bounded by the open timeout
+func (b *backend) head(ctx context.Context) (*s3.HeadObjectOutput, error) {
+	ctx, cancel := withTimeout(ctx, b.opts.openTimeout)
+	defer cancel()
+	out, err := b.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.key),
+	}, b.opts.apiOptions...)
+	if err != nil {
+		return nil, mapError("head object", err)
+	}
+	return out, nil
+}
+
+func (b *backend) kmsKeyID() *string {
+	if b.opts.sseKMSKeyID == "" {
+		return nil
+	}
+	return aws.String(b.opts.sseKMSKeyID)
+}
+
+func (b *backend) contentType() *string {
+	if b.opts.contentType == "" {
+		return nil
+	}
+	return aws.String(b.opts.contentType)
+}
+
+// withTimeout derives a context with timeout d from ctx, or returns ctx
+// unchanged if d is not positive
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
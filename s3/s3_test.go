@@ -0,0 +1,62 @@
+package s3_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/s3"
+)
+
+// newClient returns a client for a fake S3 endpoint
+func newClient() *awss3.Client {
+	return awss3.New(awss3.Options{
+		Region:                     "us-east-1",
+		Credentials:                credentials.NewStaticCredentialsProvider("key", "secret", ""),
+		RequestChecksumCalculation: aws.RequestChecksumCalculationWhenRequired,
+		ResponseChecksumValidation: aws.ResponseChecksumValidationWhenRequired,
+	})
+}
+
+func TestHeadTimeout(t *testing.T) {
+	// The server never answers in time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	b := s3.New(newClient(), "bucket", "object",
+		s3.WithEndpoint(srv.URL), s3.WithPathStyle(true), s3.WithOpenTimeout(50*time.Millisecond))
+
+	checks := map[string]func() error{
+		"Size": func() error {
+			_, err := b.(storage.Sizer).Size()
+			return err
+		},
+		"Exists": func() error {
+			_, err := storage.Exists(b)
+			return err
+		},
+	}
+	for name, check := range checks {
+		start := time.Now()
+		err := check()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("%s: got %v, want DeadlineExceeded", name, err)
+		}
+		if d := time.Since(start); d > 5*time.Second {
+			t.Errorf("%s took %v despite the open timeout", name, d)
+		}
+	}
+}
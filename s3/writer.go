@@ -0,0 +1,299 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var (
+	errWriterClosed = errors.New("s3: write on closed writer")
+	errSizeExceeded = errors.New("s3: write exceeds declared object size")
+)
+
+// writer buffers one part at a time and uploads it via the multipart API.
+// Objects smaller than a single part are stored with one PutObject call.
+type writer struct {
+	ctx context.Context
+	b   *backend
+
+	// single disables multipart uploads for objects of a known size
+	single bool
+
+	// metadata is captured on Create so later SetMetadata calls do not
+	// affect an upload in progress
+	metadata map[string]string
+
+	// tagging is the URL-encoded object tag set, if any
+	tagging *string
+
+	// mu serializes the writer methods with the abort on cancellation
+	mu   sync.Mutex
+	stop func() bool
+
+	buf      []byte
+	uploadID *string
+	parts    []types.CompletedPart
+	err      error
+	closed   bool
+}
+
+func newWriter(ctx context.Context, b *backend) *writer {
+	return &writer{
+		ctx:      ctx,
+		b:        b,
+		metadata: b.currentMetadata(),
+		buf:      make([]byte, 0, b.opts.partSize),
+	}
+}
+
+// newSingleWriter returns a writer that uploads exactly one PutObject
+// of at most size bytes
+func newSingleWriter(ctx context.Context, b *backend, size int64) *writer {
+	return &writer{
+		ctx:      ctx,
+		b:        b,
+		single:   true,
+		metadata: b.currentMetadata(),
+		buf:      make([]byte, 0, size),
+	}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, errWriterClosed
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.single {
+		if len(p) > cap(w.buf)-len(w.buf) {
+			w.err = errSizeExceeded
+			return 0, w.err
+		}
+		w.buf = append(w.buf, p...)
+		return len(p), nil
+	}
+
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), cap(w.buf)-len(w.buf))
+		w.buf = append(w.buf, p[:n]...)
+		p = p[n:]
+		written += n
+
+		if len(w.buf) == cap(w.buf) {
+			if err := w.uploadPart(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// ReadFrom reads from src directly into the part buffer, so no
+// intermediate copy buffer is needed
+func (w *writer) ReadFrom(src io.Reader) (int64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, errWriterClosed
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	var total int64
+	for {
+		if len(w.buf) == cap(w.buf) {
+			if w.single {
+				// Data beyond the declared size is an error, EOF is not
+				var probe [1]byte
+				n, err := io.ReadFull(src, probe[:])
+				if n > 0 {
+					w.err = errSizeExceeded
+					return total, w.err
+				}
+				if err == io.EOF {
+					return total, nil
+				}
+				return total, err
+			}
+			if err := w.uploadPart(); err != nil {
+				return total, err
+			}
+		}
+
+		n, err := src.Read(w.buf[len(w.buf):cap(w.buf)])
+		w.buf = w.buf[:len(w.buf)+n]
+		total += int64(n)
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+func (w *writer) Close() error {
+	if w.stop != nil {
+		w.stop()
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return w.err
+	}
+	w.closed = true
+	if w.err != nil {
+		return w.err
+	}
+
+	if w.uploadID == nil {
+		w.err = w.putObject()
+		return w.err
+	}
+	if len(w.buf) > 0 {
+		if err := w.uploadPart(); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := withTimeout(w.ctx, w.b.opts.createTimeout)
+	defer cancel()
+	_, err := w.b.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(w.b.bucket),
+		Key:             aws.String(w.b.key),
+		UploadId:        w.uploadID,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: w.parts},
+	}, w.b.opts.apiOptions...)
+	if err != nil {
+		w.fail(mapError("complete multipart upload", err))
+		return w.err
+	}
+	return nil
+}
+
+func (w *writer) putObject() error {
+	ctx, cancel := withTimeout(w.ctx, w.b.opts.createTimeout)
+	defer cancel()
+	_, err := w.b.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:               aws.String(w.b.bucket),
+		Key:                  aws.String(w.b.key),
+		Body:                 bytes.NewReader(w.buf),
+		ContentLength:        aws.Int64(int64(len(w.buf))),
+		ContentType:          w.b.contentType(),
+		Metadata:             w.metadata,
+		Tagging:              w.tagging,
+		ServerSideEncryption: w.b.opts.sse,
+		SSEKMSKeyId:          w.b.kmsKeyID(),
+		StorageClass:         w.b.opts.storageClass,
+	}, w.b.opts.apiOptions...)
+	if err != nil {
+		return mapError("put object", err)
+	}
+	return nil
+}
+
+func (w *writer) uploadPart() error {
+	if w.uploadID == nil {
+		if err := w.createUpload(); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := withTimeout(w.ctx, w.b.opts.createTimeout)
+	defer cancel()
+
+	partNumber := aws.Int32(int32(len(w.parts) + 1))
+	out, err := w.b.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:            aws.String(w.b.bucket),
+		Key:               aws.String(w.b.key),
+		UploadId:          w.uploadID,
+		PartNumber:        partNumber,
+		Body:              bytes.NewReader(w.buf),
+		ContentLength:     aws.Int64(int64(len(w.buf))),
+		ChecksumAlgorithm: types.ChecksumAlgorithmCrc32,
+	}, w.b.opts.apiOptions...)
+	if err != nil {
+		w.fail(mapError("upload part", err))
+		return w.err
+	}
+
+	w.parts = append(w.parts, types.CompletedPart{
+		ETag:          out.ETag,
+		PartNumber:    partNumber,
+		ChecksumCRC32: out.ChecksumCRC32,
+	})
+	w.buf = w.buf[:0]
+	return nil
+}
+
+func (w *writer) createUpload() error {
+	ctx, cancel := withTimeout(w.ctx, w.b.opts.createTimeout)
+	defer cancel()
+
+	out, err := w.b.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:               aws.String(w.b.bucket),
+		Key:                  aws.String(w.b.key),
+		ChecksumAlgorithm:    types.ChecksumAlgorithmCrc32,
+		ContentType:          w.b.contentType(),
+		Metadata:             w.metadata,
+		Tagging:              w.tagging,
+		ServerSideEncryption: w.b.opts.sse,
+		SSEKMSKeyId:          w.b.kmsKeyID(),
+		StorageClass:         w.b.opts.storageClass,
+	}, w.b.opts.apiOptions...)
+	if err != nil {
+		w.err = mapError("create multipart upload", err)
+		return w.err
+	}
+	w.uploadID = out.UploadId
+	return nil
+}
+
+// abortOnDone aborts the upload as soon as w.ctx is done, even if the
+// writer is never closed
+func (w *writer) abortOnDone() {
+	w.stop = context.AfterFunc(w.ctx, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if w.closed || w.err != nil {
+			return
+		}
+		w.fail(fmt.Errorf("s3: upload %s: %w", w.b.key, w.ctx.Err()))
+	})
+}
+
+// fail records err and aborts the multipart upload so no parts are leaked
+func (w *writer) fail(err error) {
+	w.err = err
+	if w.uploadID == nil {
+		return
+	}
+	// Abort even if the writer's context has been cancelled
+	ctx, cancel := withTimeout(context.WithoutCancel(w.ctx), w.b.opts.createTimeout)
+	defer cancel()
+	_, abortErr := w.b.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(w.b.bucket),
+		Key:      aws.String(w.b.key),
+		UploadId: w.uploadID,
+	}, w.b.opts.apiOptions...)
+	if abortErr != nil {
+		w.err = errors.Join(err, mapError("abort multipart upload", abortErr))
+	}
+	w.uploadID = nil
+}
@@ -0,0 +1,22 @@
+package storage
+
+import "io"
+
+// OpenSeeker opens the storage location of b for random access.
+// Backends opt in by returning an io.ReadSeekCloser from Open; otherwise
+// the reader is closed and ErrNotSeekable is returned.
+//
+// Seeking backward may be expensive for remote backends, which usually
+// have to issue a new request for every change of position.
+func OpenSeeker(b Backend) (io.ReadSeekCloser, error) {
+	r, err := b.Open()
+	if err != nil {
+		return nil, err
+	}
+	rs, ok := r.(io.ReadSeekCloser)
+	if !ok {
+		r.Close()
+		return nil, ErrNotSeekable
+	}
+	return rs, nil
+}
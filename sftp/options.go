@@ -0,0 +1,15 @@
+package sftp
+
+// Option configures the SFTP backend
+type Option func(*options)
+
+type options struct {
+	mkdirAll bool
+}
+
+// WithMkdirAll creates missing parent directories of the remote file on Create
+func WithMkdirAll(enabled bool) Option {
+	return func(o *options) {
+		o.mkdirAll = enabled
+	}
+}
@@ -0,0 +1,103 @@
+// Package sftp provides a storage backend that keeps data in a file on
+// an SFTP server.
+//
+// Backend methods are safe for concurrent use as far as the underlying
+// client is. Data is written to the remote file directly, so Open during
+// a write may observe partial data.
+package sftp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+
+	"github.com/pkg/sftp"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+type backend struct {
+	client *sftp.Client
+	path   string
+	opts   options
+}
+
+// New creates an SFTP backend storing its data in the remote file at path
+func New(client *sftp.Client, path string, opts ...Option) storage.Backend {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &backend{client: client, path: path, opts: o}
+}
+
+// Create creates or truncates the remote file and returns a writer for it
+func (b *backend) Create() (io.WriteCloser, error) {
+	if b.opts.mkdirAll {
+		if err := b.client.MkdirAll(path.Dir(b.path)); err != nil {
+			return nil, mapError("mkdir", err)
+		}
+	}
+	f, err := b.client.OpenFile(b.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return nil, mapError("create", err)
+	}
+	return f, nil
+}
+
+// Open opens the remote file for reading. The returned reader is seekable.
+func (b *backend) Open() (io.ReadCloser, error) {
+	f, err := b.client.Open(b.path)
+	if err != nil {
+		return nil, mapError("open", err)
+	}
+	return f, nil
+}
+
+// Remove deletes the remote file. Removing a missing file is a no-op.
+func (b *backend) Remove() error {
+	if err := b.client.Remove(b.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return mapError("remove", err)
+	}
+	return nil
+}
+
+// Size returns the size of the remote file
+func (b *backend) Size() (int64, error) {
+	fi, err := b.client.Stat(b.path)
+	if err != nil {
+		return 0, mapError("stat", err)
+	}
+	return fi.Size(), nil
+}
+
+// Exists reports whether the remote file exists
+func (b *backend) Exists() (bool, error) {
+	_, err := b.client.Stat(b.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, mapError("stat", err)
+	}
+	return true, nil
+}
+
+// mapError wraps err and maps SFTP failures onto the storage sentinel errors
+func mapError(op string, err error) error {
+	var sentinel error
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		sentinel = storage.ErrNotFound
+	case errors.Is(err, fs.ErrExist):
+		sentinel = storage.ErrAlreadyExists
+	case errors.Is(err, fs.ErrPermission):
+		sentinel = storage.ErrPermission
+	default:
+		return fmt.Errorf("sftp: %s: %w", op, err)
+	}
+	return fmt.Errorf("sftp: %s: %w: %w", op, sentinel, err)
+}
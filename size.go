@@ -0,0 +1,24 @@
+package storage
+
+// Sizer is implemented by backends that can report the stored size
+// without reading the data back
+type Sizer interface {
+	// Size returns the number of bytes currently held by the location.
+	// It returns an error wrapping ErrNotFound if the location does not exist.
+	Size() (int64, error)
+}
+
+// SizeOf returns the stored size of b and whether it could be determined.
+// The result is false if b does not implement Sizer or Size fails; use
+// Sizer directly to inspect the error.
+func SizeOf(b Backend) (int64, bool) {
+	s, ok := b.(Sizer)
+	if !ok {
+		return 0, false
+	}
+	n, err := s.Size()
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
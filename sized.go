@@ -0,0 +1,21 @@
+package storage
+
+import "io"
+
+// SizedCreator is implemented by backends that can use a more efficient
+// write path when the final size is known up front
+type SizedCreator interface {
+	// CreateSized is like Create with a hint of the number of bytes that
+	// will be written. A negative size means the size is unknown.
+	CreateSized(size int64) (io.WriteCloser, error)
+}
+
+// CreateSized creates a storage location on b for size bytes.
+// It falls back to Create if b does not implement SizedCreator or size
+// is negative.
+func CreateSized(b Backend, size int64) (io.WriteCloser, error) {
+	if sc, ok := b.(SizedCreator); ok && size >= 0 {
+		return sc.CreateSized(size)
+	}
+	return b.Create()
+}
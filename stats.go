@@ -0,0 +1,35 @@
+package storage
+
+// BackendStats holds operation counters of a backend
+type BackendStats struct {
+	// Creates, Opens and Removes count the calls of the methods,
+	// including failed ones
+	Creates int64
+	Opens   int64
+	Removes int64
+
+	// BytesWritten and BytesRead count the bytes transferred through
+	// the streams returned by Create and Open
+	BytesWritten int64
+	BytesRead    int64
+
+	// Errors counts failed method calls and stream operations; io.EOF
+	// is not an error
+	Errors int64
+}
+
+// StatsReporter is implemented by backends that track their operations
+// without a wrapper. Stats may be called while operations are in flight.
+type StatsReporter interface {
+	// Stats returns a snapshot of the counters since the backend was created
+	Stats() BackendStats
+}
+
+// GetStats returns the counters of b and whether b implements StatsReporter
+func GetStats(b Backend) (BackendStats, bool) {
+	s, ok := b.(StatsReporter)
+	if !ok {
+		return BackendStats{}, false
+	}
+	return s.Stats(), true
+}
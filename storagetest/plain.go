@@ -0,0 +1,39 @@
+package storagetest
+
+import (
+	"io"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+// Plain returns a backend that hides every optional interface of b and of
+// its streams, such as io.WriterTo, io.ReaderFrom and io.Seeker, so that
+// callers take their generic code paths. Benchmarks and tests use it to
+// compare a backend's fast paths with the buffered fallback.
+func Plain(b storage.Backend) storage.Backend {
+	return plain{b}
+}
+
+type plain struct {
+	b storage.Backend
+}
+
+func (p plain) Create() (io.WriteCloser, error) {
+	w, err := p.b.Create()
+	if err != nil {
+		return nil, err
+	}
+	return struct{ io.WriteCloser }{w}, nil
+}
+
+func (p plain) Open() (io.ReadCloser, error) {
+	r, err := p.b.Open()
+	if err != nil {
+		return nil, err
+	}
+	return struct{ io.ReadCloser }{r}, nil
+}
+
+func (p plain) Remove() error {
+	return p.b.Remove()
+}
@@ -0,0 +1,306 @@
+// Package storagetest provides a conformance suite for storage.Backend
+// implementations and helpers for testing them.
+//
+// A backend's own tests call RunSuite with a factory for fresh, empty
+// backends:
+//
+//	func TestBackend(t *testing.T) {
+//		storagetest.RunSuite(t, func() storage.Backend {
+//			return filesystem.New(t.TempDir())
+//		})
+//	}
+package storagetest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+)
+
+// LargePayloadSize is the size of the payload streamed by the large payload test
+const LargePayloadSize = 8<<20 + 123
+
+// RunSuite runs the conformance suite against backends returned by factory.
+// Every call to factory must return a fresh, empty backend. Sub-suites for
+// optional capabilities run only if the backend provides them.
+func RunSuite(t *testing.T, factory func() storage.Backend) {
+	t.Helper()
+
+	tests := []struct {
+		name string
+		fn   func(*testing.T, storage.Backend)
+	}{
+		{"RoundTrip", testRoundTrip},
+		{"OpenBeforeCreate", testOpenBeforeCreate},
+		{"RemoveThenOpen", testRemoveThenOpen},
+		{"RemoveIdempotent", testRemoveIdempotent},
+		{"EmptyPayload", testEmptyPayload},
+		{"LargePayload", testLargePayload},
+		{"Overwrite", testOverwrite},
+		{"Sizer", testSizer},
+		{"Exister", testExister},
+		{"Seeker", testSeeker},
+		{"StatsReporter", testStatsReporter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := factory()
+			t.Cleanup(func() { b.Remove() })
+			tt.fn(t, b)
+		})
+	}
+}
+
+func testRoundTrip(t *testing.T, b storage.Backend) {
+	data := payload(4096)
+	write(t, b, data)
+	if got := read(t, b); !bytes.Equal(got, data) {
+		t.Fatalf("read %d bytes, want the %d bytes written", len(got), len(data))
+	}
+}
+
+func testOpenBeforeCreate(t *testing.T, b storage.Backend) {
+	r, err := b.Open()
+	if err == nil {
+		r.Close()
+		t.Fatal("Open before Create succeeded")
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("Open before Create: got %v, want ErrNotFound", err)
+	}
+}
+
+func testRemoveThenOpen(t *testing.T, b storage.Backend) {
+	write(t, b, payload(128))
+	if err := b.Remove(); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+
+	r, err := b.Open()
+	if err == nil {
+		r.Close()
+		t.Fatal("Open after Remove succeeded")
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("Open after Remove: got %v, want ErrNotFound", err)
+	}
+}
+
+func testRemoveIdempotent(t *testing.T, b storage.Backend) {
+	if err := b.Remove(); err != nil {
+		t.Fatalf("Remove before Create: %v", err)
+	}
+	write(t, b, payload(128))
+	for i := range 2 {
+		if err := b.Remove(); err != nil {
+			t.Fatalf("Remove #%d: %v", i+1, err)
+		}
+	}
+}
+
+func testEmptyPayload(t *testing.T, b storage.Backend) {
+	write(t, b, nil)
+	if got := read(t, b); len(got) != 0 {
+		t.Fatalf("read %d bytes, want 0", len(got))
+	}
+}
+
+func testLargePayload(t *testing.T, b storage.Backend) {
+	data := payload(LargePayloadSize)
+
+	w, err := b.Create()
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	// Write in uneven chunks to exercise buffering boundaries
+	for rest, n := data, 1; len(rest) > 0; n = n*3 + 1 {
+		n = min(n%(1<<20)+1, len(rest))
+		if _, err := w.Write(rest[:n]); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		rest = rest[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close writer: %v", err)
+	}
+
+	if got := read(t, b); !bytes.Equal(got, data) {
+		t.Fatalf("read %d bytes, want the %d bytes written", len(got), len(data))
+	}
+}
+
+func testOverwrite(t *testing.T, b storage.Backend) {
+	write(t, b, payload(1024))
+	data := []byte("replacement")
+	write(t, b, data)
+	if got := read(t, b); !bytes.Equal(got, data) {
+		t.Fatalf("read %q, want %q", got, data)
+	}
+}
+
+func testSizer(t *testing.T, b storage.Backend) {
+	s, ok := b.(storage.Sizer)
+	if !ok {
+		t.Skip("backend does not implement storage.Sizer")
+	}
+
+	if _, err := s.Size(); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("Size before Create: got %v, want ErrNotFound", err)
+	}
+	data := payload(3000)
+	write(t, b, data)
+	n, err := s.Size()
+	if err != nil {
+		t.Fatalf("Size: %v", err)
+	}
+	if n != int64(len(data)) {
+		t.Fatalf("Size = %d, want %d", n, len(data))
+	}
+}
+
+func testExister(t *testing.T, b storage.Backend) {
+	e, ok := b.(storage.Exister)
+	if !ok {
+		t.Skip("backend does not implement storage.Exister")
+	}
+
+	check := func(when string, want bool) {
+		t.Helper()
+		got, err := e.Exists()
+		if err != nil {
+			t.Fatalf("Exists %s: %v", when, err)
+		}
+		if got != want {
+			t.Fatalf("Exists %s = %v, want %v", when, got, want)
+		}
+	}
+
+	check("before Create", false)
+	write(t, b, payload(16))
+	check("after Create", true)
+	if err := b.Remove(); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	check("after Remove", false)
+}
+
+func testSeeker(t *testing.T, b storage.Backend) {
+	data := payload(10000)
+	write(t, b, data)
+
+	r, err := b.Open()
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer r.Close()
+	rs, ok := r.(io.ReadSeekCloser)
+	if !ok {
+		t.Skip("backend does not return an io.ReadSeekCloser")
+	}
+
+	// Every seek is followed by a 100 byte read, which SeekCurrent accounts for
+	seeks := []struct {
+		offset int64
+		whence int
+		want   int64
+	}{
+		{5000, io.SeekStart, 5000},
+		{-1000, io.SeekCurrent, 4100},
+		{-10, io.SeekEnd, int64(len(data)) - 10},
+		{0, io.SeekStart, 0},
+	}
+	for _, s := range seeks {
+		pos, err := rs.Seek(s.offset, s.whence)
+		if err != nil {
+			t.Fatalf("Seek(%d, %d): %v", s.offset, s.whence, err)
+		}
+		if pos != s.want {
+			t.Fatalf("Seek(%d, %d) = %d, want %d", s.offset, s.whence, pos, s.want)
+		}
+
+		buf := make([]byte, 100)
+		n, err := io.ReadFull(rs, buf)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			t.Fatalf("Read at %d: %v", pos, err)
+		}
+		if !bytes.Equal(buf[:n], data[pos:min(pos+100, int64(len(data)))]) {
+			t.Fatalf("Read at %d returned wrong data", pos)
+		}
+	}
+}
+
+func testStatsReporter(t *testing.T, b storage.Backend) {
+	if _, ok := storage.GetStats(b); !ok {
+		t.Skip("backend does not implement storage.StatsReporter")
+	}
+
+	data := payload(3000)
+	write(t, b, data)
+	read(t, b)
+	if err := b.Remove(); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if r, err := b.Open(); err == nil {
+		r.Close()
+		t.Fatal("Open after Remove succeeded")
+	}
+
+	got, _ := storage.GetStats(b)
+	want := storage.BackendStats{
+		Creates:      1,
+		Opens:        2,
+		Removes:      1,
+		BytesWritten: int64(len(data)),
+		BytesRead:    int64(len(data)),
+		Errors:       1,
+	}
+	if got != want {
+		t.Fatalf("Stats = %+v, want %+v", got, want)
+	}
+}
+
+// payload returns n bytes of deterministic pseudo-random data
+func payload(n int) []byte {
+	rng := rand.New(rand.NewPCG(uint64(n), 0x9e3779b97f4a7c15))
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(rng.UintN(256))
+	}
+	return data
+}
+
+func write(t *testing.T, b storage.Backend, data []byte) {
+	t.Helper()
+	w, err := b.Create()
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close writer: %v", err)
+	}
+}
+
+func read(t *testing.T, b storage.Backend) []byte {
+	t.Helper()
+	r, err := b.Open()
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		r.Close()
+		t.Fatalf("Read: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close reader: %v", err)
+	}
+	return data
+}
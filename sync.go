@@ -0,0 +1,21 @@
+package storage
+
+import "io"
+
+// Syncer is implemented by writers that can make the data written so far
+// durable without closing the stream. Writing may continue after Sync.
+type Syncer interface {
+	// Sync commits buffered data to the backend.
+	// For files this flushes the data to stable storage.
+	Sync() error
+}
+
+// Sync commits the data written to w so far if w implements Syncer.
+// It is a no-op for writers that do not support syncing.
+func Sync(w io.WriteCloser) error {
+	s, ok := w.(Syncer)
+	if !ok {
+		return nil
+	}
+	return s.Sync()
+}
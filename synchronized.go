@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"io"
+	"sync"
+)
+
+type synchronizedBackend struct {
+	mu    sync.Mutex
+	inner Backend
+}
+
+// Synchronized returns a backend that serializes Create, Open and Remove
+// on b, making a non-thread-safe backend safe for concurrent calls.
+//
+// The lock is only held while a stream is being established, not for the
+// lifetime of the returned reader or writer, so several streams can be
+// used concurrently. The wrapper therefore does not protect b from uses
+// the Backend contract leaves undefined, such as opening a location while
+// a writer is still open; streams that share state with b must be safe
+// for that themselves.
+func Synchronized(b Backend) Backend {
+	return &synchronizedBackend{inner: b}
+}
+
+func (s *synchronizedBackend) Create() (io.WriteCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inner.Create()
+}
+
+func (s *synchronizedBackend) Open() (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inner.Open()
+}
+
+func (s *synchronizedBackend) Remove() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inner.Remove()
+}
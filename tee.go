@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+type teeBackend struct {
+	primary   Backend
+	secondary Backend
+}
+
+// Tee returns a backend that writes to primary and secondary at once.
+// Reads are served by primary and fall back to secondary only if primary
+// reports ErrNotFound. Remove removes both locations.
+//
+// Failures of either side are reported as a joined error that names the
+// failing side, even if the other side succeeded. The first failed Write
+// aborts the upload on both sides: both writers are closed and both
+// locations removed, so neither keeps partial data.
+func Tee(primary, secondary Backend) Backend {
+	return &teeBackend{primary: primary, secondary: secondary}
+}
+
+// Create creates both locations and returns a writer fanning out to them
+func (t *teeBackend) Create() (io.WriteCloser, error) {
+	pw, err := t.primary.Create()
+	if err != nil {
+		return nil, teeError(fmt.Errorf("primary: %w", err), nil)
+	}
+	sw, err := t.secondary.Create()
+	if err != nil {
+		serr := fmt.Errorf("secondary: %w", err)
+		pw.Close()
+		if rerr := t.primary.Remove(); rerr != nil {
+			return nil, teeError(fmt.Errorf("primary: remove: %w", rerr), serr)
+		}
+		return nil, teeError(nil, serr)
+	}
+	return &teeWriter{t: t, primary: pw, secondary: sw}, nil
+}
+
+// Open opens primary, or secondary if primary does not exist
+func (t *teeBackend) Open() (io.ReadCloser, error) {
+	r, err := t.primary.Open()
+	if err == nil {
+		return r, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, teeError(fmt.Errorf("primary: %w", err), nil)
+	}
+
+	// A missing primary is expected here, so only the secondary is reported
+	r, err = t.secondary.Open()
+	if err != nil {
+		return nil, teeError(nil, fmt.Errorf("secondary: %w", err))
+	}
+	return r, nil
+}
+
+// Remove removes both locations
+func (t *teeBackend) Remove() error {
+	var perr, serr error
+	if err := t.primary.Remove(); err != nil {
+		perr = fmt.Errorf("primary: %w", err)
+	}
+	if err := t.secondary.Remove(); err != nil {
+		serr = fmt.Errorf("secondary: %w", err)
+	}
+	return teeError(perr, serr)
+}
+
+// teeWriter writes every chunk to both writers and aborts at the first error
+type teeWriter struct {
+	t         *teeBackend
+	primary   io.WriteCloser
+	secondary io.WriteCloser
+	err       error
+	closed    bool
+}
+
+func (w *teeWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if _, err := w.primary.Write(p); err != nil {
+		w.abort(fmt.Errorf("primary: %w", err), nil)
+		return 0, w.err
+	}
+	if _, err := w.secondary.Write(p); err != nil {
+		w.abort(nil, fmt.Errorf("secondary: %w", err))
+		return 0, w.err
+	}
+	return len(p), nil
+}
+
+// abort closes both writers and removes both locations after a failed
+// Write. Errors of the cleanup are joined to the write error.
+func (w *teeWriter) abort(perr, serr error) {
+	w.closed = true
+	w.primary.Close()
+	w.secondary.Close()
+	if err := w.t.primary.Remove(); err != nil {
+		perr = errors.Join(perr, fmt.Errorf("primary: remove: %w", err))
+	}
+	if err := w.t.secondary.Remove(); err != nil {
+		serr = errors.Join(serr, fmt.Errorf("secondary: remove: %w", err))
+	}
+	w.err = teeError(perr, serr)
+}
+
+// Close closes both writers and reports the errors of either side
+func (w *teeWriter) Close() error {
+	if w.closed {
+		return w.err
+	}
+	w.closed = true
+
+	var perr, serr error
+	if err := w.primary.Close(); err != nil {
+		perr = fmt.Errorf("primary: %w", err)
+	}
+	if err := w.secondary.Close(); err != nil {
+		serr = fmt.Errorf("secondary: %w", err)
+	}
+	if err := teeError(perr, serr); err != nil {
+		w.err = errors.Join(w.err, err)
+	}
+	return w.err
+}
+
+// teeError joins the errors of both sides, or returns nil if there are none
+func teeError(primary, secondary error) error {
+	if err := errors.Join(primary, secondary); err != nil {
+		return fmt.Errorf("storage: tee: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package storage_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/memory"
+)
+
+var errBroken = errors.New("broken")
+
+// broken wraps a backend and fails Open or Write with the configured errors
+type broken struct {
+	storage.Backend
+	createErr error
+	openErr   error
+	writeErr  error
+}
+
+func (b *broken) Create() (io.WriteCloser, error) {
+	if b.createErr != nil {
+		return nil, b.createErr
+	}
+	w, err := b.Backend.Create()
+	if err != nil {
+		return nil, err
+	}
+	return &brokenWriter{WriteCloser: w, err: b.writeErr}, nil
+}
+
+func (b *broken) Open() (io.ReadCloser, error) {
+	if b.openErr != nil {
+		return nil, b.openErr
+	}
+	return b.Backend.Open()
+}
+
+type brokenWriter struct {
+	io.WriteCloser
+	err error
+}
+
+func (w *brokenWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	return w.WriteCloser.Write(p)
+}
+
+func TestTeeWritesBoth(t *testing.T) {
+	primary, secondary := memory.New(), memory.New()
+	write(t, storage.Tee(primary, secondary), "data")
+
+	if got := read(t, primary); got != "data" {
+		t.Fatalf("primary holds %q", got)
+	}
+	if got := read(t, secondary); got != "data" {
+		t.Fatalf("secondary holds %q", got)
+	}
+}
+
+func TestTeeOpenFallsBack(t *testing.T) {
+	primary, secondary := memory.New(), memory.New()
+	write(t, secondary, "backup")
+
+	if got := read(t, storage.Tee(primary, secondary)); got != "backup" {
+		t.Fatalf("got %q, want the secondary's data", got)
+	}
+}
+
+func TestTeeOpenPrimaryError(t *testing.T) {
+	secondary := memory.New()
+	write(t, secondary, "backup")
+	tee := storage.Tee(&broken{Backend: memory.New(), openErr: errBroken}, secondary)
+
+	if _, err := tee.Open(); !errors.Is(err, errBroken) {
+		t.Fatalf("got %v, want the primary's error without a fallback", err)
+	}
+}
+
+func TestTeeOpenSecondaryError(t *testing.T) {
+	tee := storage.Tee(memory.New(), &broken{Backend: memory.New(), openErr: errBroken})
+
+	_, err := tee.Open()
+	if !errors.Is(err, errBroken) {
+		t.Fatalf("got %v, want the secondary's error", err)
+	}
+	if errors.Is(err, storage.ErrNotFound) || strings.Contains(err.Error(), "primary") {
+		t.Fatalf("got %v, want only the secondary's error", err)
+	}
+}
+
+func TestTeeWriteFailureAborts(t *testing.T) {
+	for _, side := range []string{"primary", "secondary"} {
+		t.Run(side, func(t *testing.T) {
+			healthy := memory.New()
+			failing := &broken{Backend: memory.New(), writeErr: errBroken}
+			tee := storage.Tee(healthy, failing)
+			if side == "primary" {
+				tee = storage.Tee(failing, healthy)
+			}
+
+			w, err := tee.Create()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := io.WriteString(w, "data"); !errors.Is(err, errBroken) {
+				t.Fatalf("Write: got %v, want the %s's error", err, side)
+			}
+			if _, err := io.WriteString(w, "more"); !errors.Is(err, errBroken) {
+				t.Fatalf("second Write: got %v", err)
+			}
+			if err := w.Close(); !errors.Is(err, errBroken) {
+				t.Fatalf("Close: got %v", err)
+			}
+
+			for name, b := range map[string]storage.Backend{"healthy": healthy, "failing": failing} {
+				if ok, err := storage.Exists(b); err != nil || ok {
+					t.Errorf("%s side exists = %v, %v after the aborted write", name, ok, err)
+				}
+			}
+		})
+	}
+}
+
+func TestTeeCreateFailure(t *testing.T) {
+	primary := memory.New()
+	tee := storage.Tee(primary, &broken{Backend: memory.New(), createErr: errBroken})
+
+	if _, err := tee.Create(); !errors.Is(err, errBroken) {
+		t.Fatalf("got %v, want the secondary's error", err)
+	}
+	if ok, _ := storage.Exists(primary); ok {
+		t.Fatal("primary was left behind")
+	}
+}
+
+func TestTeeRemove(t *testing.T) {
+	primary, secondary := memory.New(), memory.New()
+	tee := storage.Tee(primary, secondary)
+	write(t, tee, "data")
+
+	if err := tee.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tee.Open(); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("Open after Remove: got %v, want ErrNotFound", err)
+	}
+}
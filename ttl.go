@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"io"
+	"time"
+)
+
+// ExpiringCreator is implemented by backends that can expire stored data
+// natively, e.g. with a key expiry or an object lifecycle rule
+type ExpiringCreator interface {
+	// CreateWithTTL creates a new storage location whose data expires ttl
+	// after it has been written. A ttl of zero means no expiry.
+	CreateWithTTL(ttl time.Duration) (io.WriteCloser, error)
+}
+
+// CreateWithTTL creates a storage location on b whose data expires after
+// ttl. A ttl <= 0 means no expiry.
+//
+// Backends without ExpiringCreator fall back to Create and a best-effort
+// Remove scheduled with time.AfterFunc, which is lost if the process
+// exits. The scheduled Remove removes whatever the location holds at that
+// time, so b must be safe for concurrent use (see Synchronized) and
+// should not be reused for another spill before ttl has elapsed.
+func CreateWithTTL(b Backend, ttl time.Duration) (io.WriteCloser, error) {
+	if ec, ok := b.(ExpiringCreator); ok {
+		return ec.CreateWithTTL(max(ttl, 0))
+	}
+	w, err := b.Create()
+	if err != nil || ttl <= 0 {
+		return w, err
+	}
+	time.AfterFunc(ttl, func() { b.Remove() })
+	return w, nil
+}
@@ -0,0 +1,76 @@
+package storage_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/memory"
+)
+
+// expiring records the ttl passed to CreateWithTTL
+type expiring struct {
+	storage.Backend
+	ttl time.Duration
+}
+
+func (e *expiring) CreateWithTTL(ttl time.Duration) (io.WriteCloser, error) {
+	e.ttl = ttl
+	return e.Create()
+}
+
+func TestCreateWithTTLNative(t *testing.T) {
+	for _, tt := range []struct{ ttl, want time.Duration }{
+		{time.Minute, time.Minute},
+		{-time.Second, 0},
+	} {
+		b := &expiring{Backend: memory.New(), ttl: -1}
+		w, err := storage.CreateWithTTL(b, tt.ttl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Close()
+		if b.ttl != tt.want {
+			t.Errorf("CreateWithTTL(%v) passed %v, want %v", tt.ttl, b.ttl, tt.want)
+		}
+	}
+}
+
+func TestCreateWithTTLFallback(t *testing.T) {
+	b := memory.New()
+	w, err := storage.CreateWithTTL(b, 20*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(w, "data")
+	w.Close()
+
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(5 * time.Millisecond) {
+		ok, err := storage.Exists(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("data did not expire")
+		}
+	}
+}
+
+func TestCreateWithTTLZero(t *testing.T) {
+	b := memory.New()
+	w, err := storage.CreateWithTTL(b, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(w, "data")
+	w.Close()
+
+	time.Sleep(20 * time.Millisecond)
+	if got := read(t, b); got != "data" {
+		t.Fatalf("got %q, want the data to stay", got)
+	}
+}
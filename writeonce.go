@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+type writeOnceBackend struct {
+	inner Backend
+
+	mu      sync.Mutex
+	created bool
+}
+
+// WriteOnce returns a backend that allows only one successful Create on b
+// until the location is removed. A second Create fails with an error
+// wrapping ErrAlreadyExists; after a successful Remove, Create is allowed
+// again. The wrapper is meant to catch locations that are accidentally
+// written twice and is safe for concurrent use: of several concurrent
+// Create calls at most one succeeds.
+func WriteOnce(b Backend) Backend {
+	return &writeOnceBackend{inner: b}
+}
+
+// Create creates the location unless it has already been created
+func (w *writeOnceBackend) Create() (io.WriteCloser, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.created {
+		return nil, fmt.Errorf("storage: write once: %w", ErrAlreadyExists)
+	}
+	wc, err := w.inner.Create()
+	if err != nil {
+		return nil, err
+	}
+	w.created = true
+	return wc, nil
+}
+
+// Open opens the wrapped location
+func (w *writeOnceBackend) Open() (io.ReadCloser, error) {
+	return w.inner.Open()
+}
+
+// Remove removes the wrapped location and allows a new Create
+func (w *writeOnceBackend) Remove() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.inner.Remove(); err != nil {
+		return err
+	}
+	w.created = false
+	return nil
+}
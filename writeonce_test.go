@@ -0,0 +1,63 @@
+package storage_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"schneider.vip/hybridbuffer/storage"
+	"schneider.vip/hybridbuffer/storage/memory"
+)
+
+func TestWriteOnce(t *testing.T) {
+	b := storage.WriteOnce(memory.New())
+	write(t, b, "first")
+
+	if _, err := b.Create(); !errors.Is(err, storage.ErrAlreadyExists) {
+		t.Fatalf("second Create: got %v, want ErrAlreadyExists", err)
+	}
+	if got := read(t, b); got != "first" {
+		t.Fatalf("got %q, want the first write", got)
+	}
+
+	if err := b.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	write(t, b, "second")
+	if got := read(t, b); got != "second" {
+		t.Fatalf("got %q after Remove and Create", got)
+	}
+}
+
+func TestWriteOnceFailedCreate(t *testing.T) {
+	inner := &broken{Backend: memory.New(), createErr: errBroken}
+	b := storage.WriteOnce(inner)
+	if _, err := b.Create(); !errors.Is(err, errBroken) {
+		t.Fatalf("got %v", err)
+	}
+
+	// A failed Create does not use up the single write
+	inner.createErr = nil
+	write(t, b, "data")
+}
+
+func TestWriteOnceConcurrent(t *testing.T) {
+	b := storage.WriteOnce(memory.New())
+	var wg sync.WaitGroup
+	var created atomic.Int32
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if w, err := b.Create(); err == nil {
+				created.Add(1)
+				w.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	if n := created.Load(); n != 1 {
+		t.Fatalf("%d concurrent Creates succeeded, want 1", n)
+	}
+}